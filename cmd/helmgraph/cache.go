@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"helmgraph/internal/cache"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cacheDir  string
+	olderThan time.Duration
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of pulled Helm charts.",
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached charts.",
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := openCache().List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CHART\tVERSION\tREPO\tDIGEST\tSIZE\tLAST USED")
+		for _, e := range entries {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\n", e.Chart, e.Version, e.Repo, e.Digest, e.Size, e.LastUsed.Format(time.RFC3339))
+		}
+		w.Flush()
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached charts.",
+	Long:  `Removes every cached chart, or only those not used within --older-than.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pruned, err := openCache().Prune(olderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, e := range pruned {
+			fmt.Printf("Removed %s %s\n", e.Chart, e.Version)
+		}
+	},
+}
+
+// openCache returns the chart cache selected by the --cache-dir flag.
+func openCache() *cache.Cache {
+	if cacheDir != "" {
+		return cache.New(cacheDir)
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return cache.New(dir)
+}
+
+func init() {
+	cacheCmd.PersistentFlags().StringVarP(&cacheDir, "cache-dir", "", "", "Chart cache directory (default: $XDG_CACHE_HOME/helmgraph/charts)")
+	cachePruneCmd.Flags().DurationVarP(&olderThan, "older-than", "", 0, "Only remove charts not used within this duration, e.g. 720h")
+	cacheCmd.AddCommand(cacheListCmd, cachePruneCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
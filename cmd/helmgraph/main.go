@@ -12,32 +12,80 @@ import (
 )
 
 var (
-	chartPath   string
-	releaseName string
-	namespace   string
-	outputFile  string
-	repo        string
+	chartPath      string
+	releaseName    string
+	namespace      string
+	outputFile     string
+	repo           string
+	chartVersion   string
+	registryConfig string
+	plainHTTP      bool
+	noCache        bool
+	renderMode     string
+	values         manifest.ValueOptions
+	manifests      []string
+	embedWarnings  bool
+	kubeVersion    string
+	apiVersions    []string
+	clusterProfile string
+	expandClaims   bool
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "helmgraph",
 	Short: "Generate a Cypher script from a Helm chart.",
-	Long:  `HelmGraph generates a Cypher script from a Helm chart that can be imported into Neo4j.`,
+	Long: `HelmGraph generates a Cypher script from a Helm chart that can be imported into Neo4j.
+
+Instead of rendering a chart, pre-rendered manifests can be read with --manifest from
+files, directories or stdin ('-').`,
 	Run: func(cmd *cobra.Command, args []string) {
-		manifest, err := manifest.Generate(chartPath, releaseName, namespace, repo)
+		if len(manifests) == 0 && releaseName == "" {
+			fmt.Fprintln(os.Stderr, "Error: required flag \"release\" not set")
+			os.Exit(1)
+		}
+
+		result, err := loadManifest()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
+		for _, d := range result.Diagnostics {
+			fmt.Fprintln(os.Stderr, d)
+		}
 
-		resources, err := parser.Parse(manifest)
+		resources, err := parser.Parse(result.Manifest)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error parsing manifest: %v\n", err)
 			os.Exit(1)
 		}
+		parser.ResolveNamespaces(resources, namespace)
+
+		if releaseName != "" {
+			resources = append(resources, releaseResource())
+		}
+		relationships := relations.IdentifyWithOptions(resources, relations.Options{ExpandClaims: expandClaims})
+		for w, directions := range relations.DefaultDeny(resources) {
+			if w.Properties == nil {
+				w.Properties = make(map[string]interface{})
+			}
+			w.Properties["defaultDeny"] = directions
+		}
+		findings := relations.Analyze(resources, relationships)
+		for _, f := range findings {
+			fmt.Fprintln(os.Stderr, f)
+		}
 
-		relationships := relations.Identify(resources)
 		cypherScript := cypher.Generate(resources, relationships)
+		if embedWarnings && len(result.Diagnostics)+len(findings) > 0 {
+			lines := make([]string, 0, len(result.Diagnostics)+len(findings))
+			for _, d := range result.Diagnostics {
+				lines = append(lines, d.String())
+			}
+			for _, f := range findings {
+				lines = append(lines, f.String())
+			}
+			cypherScript = cypher.Comment(lines...) + cypherScript
+		}
 
 		if outputFile != "" {
 			err := os.WriteFile(outputFile, []byte(cypherScript), 0644)
@@ -51,6 +99,95 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// loadManifest reads the pre-rendered manifests if any were given, otherwise it renders the chart.
+func loadManifest() (*manifest.Result, error) {
+	if len(manifests) > 0 {
+		m, err := manifest.Read(manifests, os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return &manifest.Result{Manifest: m}, nil
+	}
+
+	capabilities, err := loadCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	return manifest.Generate(manifest.Options{
+		ChartPath:      chartPath,
+		ReleaseName:    releaseName,
+		Namespace:      namespace,
+		Repo:           repo,
+		Version:        chartVersion,
+		RegistryConfig: registryConfig,
+		PlainHTTP:      plainHTTP,
+		CacheDir:       cacheDir,
+		NoCache:        noCache,
+		Mode:           manifest.Mode(renderMode),
+		Values:         values,
+		Capabilities:   capabilities,
+	})
+}
+
+// loadCapabilities reads the cluster profile, if any, and applies the --kube-version and
+// --api-versions flags on top of it.
+func loadCapabilities() (manifest.Capabilities, error) {
+	var capabilities manifest.Capabilities
+	if clusterProfile != "" {
+		var err error
+		if capabilities, err = manifest.LoadProfile(clusterProfile); err != nil {
+			return capabilities, err
+		}
+	}
+	if kubeVersion != "" {
+		capabilities.KubeVersion = kubeVersion
+	}
+	capabilities.APIVersions = apiVersions
+	return capabilities, nil
+}
+
+// releaseResource returns the Release node recording which chart and values the graph was rendered from.
+func releaseResource() *parser.Resource {
+	return &parser.Resource{
+		Kind: "Release",
+		Metadata: parser.Metadata{
+			Name:      releaseName,
+			Namespace: releaseNamespace(),
+		},
+		Properties: releaseProperties(),
+	}
+}
+
+// releaseNamespace returns the namespace the release is rendered into.
+func releaseNamespace() string {
+	if namespace == "" {
+		return parser.DefaultNamespace
+	}
+	return namespace
+}
+
+func releaseProperties() map[string]interface{} {
+	if len(manifests) > 0 {
+		return map[string]interface{}{
+			"manifests": manifests,
+		}
+	}
+	properties := map[string]interface{}{
+		"chart":  chartPath,
+		"values": values.Sources(),
+	}
+	if chartVersion != "" {
+		properties["version"] = chartVersion
+	}
+	if kubeVersion != "" {
+		properties["kubeVersion"] = kubeVersion
+	}
+	if clusterProfile != "" {
+		properties["clusterProfile"] = clusterProfile
+	}
+	return properties
+}
+
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Println(err)
@@ -59,14 +196,31 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&chartPath, "chart", "c", "", "Path to the Helm chart directory")
+	rootCmd.Flags().StringVarP(&chartPath, "chart", "c", "", "Path to the Helm chart directory, chart name in --repo, or oci:// reference")
 	rootCmd.Flags().StringVarP(&releaseName, "release", "r", "", "Release name")
 	rootCmd.Flags().StringVarP(&namespace, "namespace", "n", "", "Namespace")
 	rootCmd.Flags().StringVarP(&outputFile, "out", "o", "", "Output file name (default: stdout)")
 
 	rootCmd.Flags().StringVarP(&repo, "repo", "", "", "Helm repository URL")
-	rootCmd.MarkFlagRequired("chart")
-	rootCmd.MarkFlagRequired("release")
+	rootCmd.Flags().StringVarP(&chartVersion, "version", "", "", "Chart version constraint for remote charts (default: latest)")
+	rootCmd.Flags().StringVarP(&registryConfig, "registry-config", "", "", "Path to the registry credentials file in docker config format (default: helm's registry config)")
+	rootCmd.Flags().BoolVarP(&plainHTTP, "plain-http", "", false, "Use insecure HTTP connections for OCI registries")
+	rootCmd.Flags().StringVarP(&cacheDir, "cache-dir", "", "", "Chart cache directory (default: $XDG_CACHE_HOME/helmgraph/charts)")
+	rootCmd.Flags().BoolVarP(&noCache, "no-cache", "", false, "Pull remote charts into a temporary directory instead of the chart cache")
+	rootCmd.Flags().StringVarP(&renderMode, "renderer", "", string(manifest.ModeSDK), "Chart renderer: 'sdk' (in-process) or 'exec' (helm binary on PATH)")
+	rootCmd.Flags().StringArrayVarP(&values.ValueFiles, "values", "f", nil, "Values file to render the chart with (can be repeated)")
+	rootCmd.Flags().StringArrayVar(&values.Values, "set", nil, "Set values on the command line (can be repeated or comma separated: key1=val1,key2=val2)")
+	rootCmd.Flags().StringArrayVar(&values.StringValues, "set-string", nil, "Set STRING values on the command line (can be repeated or comma separated: key1=val1,key2=val2)")
+	rootCmd.Flags().StringArrayVar(&values.FileValues, "set-file", nil, "Set values from files specified on the command line (can be repeated or comma separated: key1=path1,key2=path2)")
+	rootCmd.Flags().StringArrayVar(&values.JSONValues, "set-json", nil, "Set JSON values on the command line (can be repeated or comma separated: key1=jsonval1,key2=jsonval2)")
+	rootCmd.Flags().StringVarP(&kubeVersion, "kube-version", "", "", "Kubernetes version used for .Capabilities.KubeVersion (default: helm's default)")
+	rootCmd.Flags().StringSliceVarP(&apiVersions, "api-versions", "a", nil, "Kubernetes api versions used for .Capabilities.APIVersions (can be repeated or comma separated)")
+	rootCmd.Flags().StringVarP(&clusterProfile, "cluster-profile", "", "", "YAML file with the kubeVersion and apiVersions served by the target cluster")
+	rootCmd.Flags().BoolVarP(&expandClaims, "expand-claims", "", false, "Add a PersistentVolumeClaim node for every replica of a StatefulSet volume claim template")
+	rootCmd.Flags().BoolVarP(&embedWarnings, "embed-warnings", "", false, "Embed rendering warnings and analysis findings as comments at the top of the Cypher script")
+	rootCmd.Flags().StringArrayVarP(&manifests, "manifest", "m", nil, "Pre-rendered manifest file or directory to read instead of a chart, '-' for stdin (can be repeated)")
+	rootCmd.MarkFlagsOneRequired("chart", "manifest")
+	rootCmd.MarkFlagsMutuallyExclusive("chart", "manifest")
 }
 
 func main() {
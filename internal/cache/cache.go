@@ -0,0 +1,309 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+)
+
+const (
+	archiveExt  = ".tgz"
+	metadataExt = ".json"
+	lockFile    = ".lock"
+	useFile     = ".use"
+)
+
+// Entry describes a chart archive stored in the cache.
+type Entry struct {
+	Repo     string    `json:"repo"`
+	Chart    string    `json:"chart"`
+	Version  string    `json:"version"`
+	Digest   string    `json:"digest"`
+	Size     int64     `json:"size"`
+	Created  time.Time `json:"created"`
+	LastUsed time.Time `json:"lastUsed"`
+	// Path is the location of the chart archive. It is not persisted.
+	Path string `json:"-"`
+}
+
+// Key returns the cache key of the entry, derived from its repository, chart, version and digest.
+func (e Entry) Key() string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{e.Repo, e.Chart, e.Version, e.Digest}, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cache stores pulled chart archives in a directory. It is safe for concurrent use
+// by several helmgraph processes sharing the same directory.
+type Cache struct {
+	dir string
+}
+
+// New returns a Cache rooted at dir.
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultDir returns the default cache directory, following the XDG base directory
+// specification on Linux ($XDG_CACHE_HOME/helmgraph/charts).
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "helmgraph", "charts"), nil
+}
+
+// Dir returns the directory the cache is rooted at.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Lookup returns the most recently stored archive for the chart version, if any.
+func (c *Cache) Lookup(repo, chart, version string) (*Entry, bool, error) {
+	lock, err := c.lock()
+	if err != nil {
+		return nil, false, err
+	}
+	defer lock.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return nil, false, err
+	}
+
+	var found *Entry
+	for i, e := range entries {
+		if e.Repo == repo && e.Chart == chart && e.Version == version {
+			if found == nil || e.Created.After(found.Created) {
+				found = &entries[i]
+			}
+		}
+	}
+	if found == nil {
+		return nil, false, nil
+	}
+
+	found.LastUsed = time.Now()
+	if err := c.writeMetadata(*found); err != nil {
+		return nil, false, err
+	}
+	return found, true, nil
+}
+
+// Store copies the chart archive into the cache and returns the stored entry. The digest and
+// size of the entry are computed from the archive; storing an identical archive again reuses
+// the existing entry.
+func (c *Cache) Store(e Entry, archive string) (*Entry, error) {
+	lock, err := c.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	src, err := os.Open(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open chart archive: %w", err)
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(c.dir, "incoming-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to write to chart cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), src)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write to chart cache: %w", err)
+	}
+
+	now := time.Now()
+	e.Digest = "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	e.Size = size
+	e.Created = now
+	e.LastUsed = now
+	e.Path = c.archivePath(e.Key())
+
+	if existing, err := c.readMetadata(e.Key()); err == nil {
+		e.Created = existing.Created
+	}
+
+	if err := os.Rename(tmp.Name(), e.Path); err != nil {
+		return nil, fmt.Errorf("failed to write to chart cache: %w", err)
+	}
+	if err := c.writeMetadata(e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// List returns all cached entries, ordered by chart name and version.
+func (c *Cache) List() ([]Entry, error) {
+	lock, err := c.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Chart != entries[j].Chart {
+			return entries[i].Chart < entries[j].Chart
+		}
+		return entries[i].Version < entries[j].Version
+	})
+	return entries, nil
+}
+
+// Use takes a shared lock on the cache and returns the function releasing it. While it is held,
+// Prune waits, so that archives returned by Lookup and Store can be read safely.
+func (c *Cache) Use() (func(), error) {
+	lock, err := c.acquire(useFile, true)
+	if err != nil {
+		return nil, err
+	}
+	return func() { lock.Unlock() }, nil
+}
+
+// Prune removes entries that have not been used for longer than olderThan and returns them.
+// A zero olderThan removes every entry. It waits until no process is using the cache.
+func (c *Cache) Prune(olderThan time.Duration) ([]Entry, error) {
+	use, err := c.acquire(useFile, false)
+	if err != nil {
+		return nil, err
+	}
+	defer use.Unlock()
+
+	lock, err := c.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer lock.Unlock()
+
+	entries, err := c.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	var pruned []Entry
+	cutoff := time.Now().Add(-olderThan)
+	for _, e := range entries {
+		if olderThan > 0 && e.LastUsed.After(cutoff) {
+			continue
+		}
+		key := e.Key()
+		if err := os.Remove(c.archivePath(key)); err != nil && !os.IsNotExist(err) {
+			return pruned, fmt.Errorf("failed to remove cached chart %s: %w", e.Chart, err)
+		}
+		if err := os.Remove(c.metadataPath(key)); err != nil && !os.IsNotExist(err) {
+			return pruned, fmt.Errorf("failed to remove cached chart %s: %w", e.Chart, err)
+		}
+		pruned = append(pruned, e)
+	}
+	return pruned, nil
+}
+
+// lock creates the cache directory if needed and takes an exclusive lock on it.
+func (c *Cache) lock() (*flock.Flock, error) {
+	return c.acquire(lockFile, false)
+}
+
+// acquire creates the cache directory if needed and takes a shared or exclusive lock on the
+// named lock file in it.
+func (c *Cache) acquire(name string, shared bool) (*flock.Flock, error) {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create chart cache directory: %w", err)
+	}
+	lock := flock.New(filepath.Join(c.dir, name))
+	take := lock.Lock
+	if shared {
+		take = lock.RLock
+	}
+	if err := take(); err != nil {
+		return nil, fmt.Errorf("failed to lock chart cache: %w", err)
+	}
+	return lock, nil
+}
+
+// entries reads the metadata of every entry. Entries whose archive is missing are skipped.
+func (c *Cache) entries() ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*"+metadataExt))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		key := strings.TrimSuffix(filepath.Base(f), metadataExt)
+		e, err := c.readMetadata(key)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(e.Path); err != nil {
+			continue
+		}
+		entries = append(entries, *e)
+	}
+	return entries, nil
+}
+
+func (c *Cache) readMetadata(key string) (*Entry, error) {
+	data, err := os.ReadFile(c.metadataPath(key))
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("failed to read cache entry %s: %w", key, err)
+	}
+	e.Path = c.archivePath(key)
+	return &e, nil
+}
+
+// writeMetadata atomically replaces the metadata file of the entry.
+func (c *Cache) writeMetadata(e Entry) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	tmp, err := os.CreateTemp(c.dir, "metadata-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.metadataPath(e.Key())); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) archivePath(key string) string {
+	return filepath.Join(c.dir, key+archiveExt)
+}
+
+func (c *Cache) metadataPath(key string) string {
+	return filepath.Join(c.dir, key+metadataExt)
+}
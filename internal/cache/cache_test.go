@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	c := New(filepath.Join(dir, "charts"))
+
+	archive := filepath.Join(dir, "mychart-1.0.0.tgz")
+	if err := os.WriteFile(archive, []byte("chart archive"), 0644); err != nil {
+		t.Fatalf("failed to write test archive: %v", err)
+	}
+
+	if _, ok, err := c.Lookup("https://charts.example.com", "mychart", "1.0.0"); err != nil || ok {
+		t.Fatalf("expected a cache miss, got ok=%v err=%v", ok, err)
+	}
+
+	// Concurrent stores of the same archive must end up as a single entry.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Store(Entry{Repo: "https://charts.example.com", Chart: "mychart", Version: "1.0.0"}, archive); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	e, ok, err := c.Lookup("https://charts.example.com", "mychart", "1.0.0")
+	if err != nil || !ok {
+		t.Fatalf("expected a cache hit, got ok=%v err=%v", ok, err)
+	}
+	data, err := os.ReadFile(e.Path)
+	if err != nil || string(data) != "chart archive" {
+		t.Errorf("unexpected cached archive content %q: %v", data, err)
+	}
+	if e.Digest == "" || e.Size != int64(len("chart archive")) {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+
+	if _, ok, _ := c.Lookup("https://other.example.com", "mychart", "1.0.0"); ok {
+		t.Error("expected entries to be keyed by repository")
+	}
+
+	entries, err := c.List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, but got %d", len(entries))
+	}
+
+	pruned, err := c.Prune(time.Hour)
+	if err != nil || len(pruned) != 0 {
+		t.Errorf("expected recently used entries to be kept, pruned %d: %v", len(pruned), err)
+	}
+	pruned, err = c.Prune(0)
+	if err != nil || len(pruned) != 1 {
+		t.Errorf("expected all entries to be pruned, pruned %d: %v", len(pruned), err)
+	}
+	if _, err := os.Stat(e.Path); !os.IsNotExist(err) {
+		t.Errorf("expected the archive to be removed, got %v", err)
+	}
+}
+
+func TestCacheUse(t *testing.T) {
+	dir := t.TempDir()
+	c := New(filepath.Join(dir, "charts"))
+
+	archive := filepath.Join(dir, "mychart-1.0.0.tgz")
+	if err := os.WriteFile(archive, []byte("chart archive"), 0644); err != nil {
+		t.Fatalf("failed to write test archive: %v", err)
+	}
+	release, err := c.Use()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e, err := c.Store(Entry{Repo: "https://charts.example.com", Chart: "mychart", Version: "1.0.0"}, archive)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := c.Prune(0); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("expected Prune to wait until the cache is released")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := os.Stat(e.Path); err != nil {
+		t.Errorf("expected the archive to be kept while in use, got %v", err)
+	}
+
+	release()
+	<-done
+	if _, err := os.Stat(e.Path); !os.IsNotExist(err) {
+		t.Errorf("expected the archive to be removed once released, got %v", err)
+	}
+}
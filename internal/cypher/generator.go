@@ -1,40 +1,190 @@
 package cypher
 
 import (
+	"encoding/json"
 	"fmt"
 	"helmgraph/internal/parser"
 	"helmgraph/internal/relations"
+	"sort"
 	"strings"
 )
 
-// Generate generates a Cypher script from a slice of resources and relationships.
+// Generate generates a Cypher script from a slice of resources and relationships. Nodes are
+// identified by their API group, namespace and name within the label of their kind, so that
+// same-named kinds of different API groups do not merge. The uniqueness constraint of each label
+// is named helmgraph_<kind>.
 func Generate(resources []*parser.Resource, relationships []*relations.Relationship) string {
 	var sb strings.Builder
+	nodes := nodes(resources, relationships)
 
 	// Generate constraints
 	kinds := make(map[string]bool)
-	for _, r := range resources {
+	for _, r := range nodes {
 		if _, ok := kinds[r.Kind]; !ok {
 			if r.Kind == "" {
 				continue
 			}
-			sb.WriteString(fmt.Sprintf("CREATE CONSTRAINT IF NOT EXISTS FOR (n:%s) REQUIRE (n.name, n.namespace) IS UNIQUE;\n", r.Kind))
+			sb.WriteString(fmt.Sprintf("CREATE CONSTRAINT helmgraph_%s IF NOT EXISTS FOR (n:%s) REQUIRE (n.group, n.name, n.namespace) IS UNIQUE;\n", r.Kind, r.Kind))
 			kinds[r.Kind] = true
 		}
 	}
 
-	// Generate nodes
-	for _, r := range resources {
+	// Generate nodes, keyed by their group-qualified identity
+	for _, r := range nodes {
 		if r.Kind == "" {
 			continue
 		}
-		sb.WriteString(fmt.Sprintf("MERGE (:%s {name: '%s', namespace: '%s', kind: '%s'});\n", r.Kind, r.Metadata.Name, r.Metadata.Namespace, r.Kind))
+		properties := nodeProperties(r)
+		labels := nodeLabels(r)
+		if len(properties) == 0 && len(labels) == 0 {
+			sb.WriteString(fmt.Sprintf("MERGE (:%s {name: %s, namespace: %s, group: %s, kind: %s});\n", r.Kind, quote(r.Metadata.Name), quote(r.Metadata.Namespace), quote(r.Group()), quote(r.Kind)))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("MERGE (n:%s {name: %s, namespace: %s, group: %s, kind: %s}) SET %s;\n", r.Kind, quote(r.Metadata.Name), quote(r.Metadata.Namespace), quote(r.Group()), quote(r.Kind), setClause("n", labels, properties)))
 	}
 
-	// Generate relationships
+	// Generate relationships, matching both ends by their full identity
 	for _, rel := range relationships {
-		sb.WriteString(fmt.Sprintf("MATCH (a:%s {name: '%s'}), (b:%s {name: '%s'}) MERGE (a)-[:%s]->(b);\n", rel.Source.Kind, rel.Source.Metadata.Name, rel.Target.Kind, rel.Target.Metadata.Name, rel.Type))
+		sb.WriteString(fmt.Sprintf("MATCH %s, %s MERGE (a)-[:%s%s]->(b);\n", matchPattern("a", rel.Source), matchPattern("b", rel.Target), rel.Type, propertyMap(rel.Properties)))
 	}
 
 	return sb.String()
 }
+
+// nodes returns the resources followed by any relationship endpoints that are not among them,
+// such as the Chart nodes created while identifying relationships.
+func nodes(resources []*parser.Resource, relationships []*relations.Relationship) []*parser.Resource {
+	seen := make(map[*parser.Resource]bool, len(resources))
+	nodes := make([]*parser.Resource, 0, len(resources))
+	for _, r := range resources {
+		seen[r] = true
+		nodes = append(nodes, r)
+	}
+	for _, rel := range relationships {
+		for _, r := range []*parser.Resource{rel.Source, rel.Target} {
+			if !seen[r] {
+				seen[r] = true
+				nodes = append(nodes, r)
+			}
+		}
+	}
+	return nodes
+}
+
+// matchPattern renders the node pattern matching r by kind, API group, namespace and name.
+func matchPattern(variable string, r *parser.Resource) string {
+	return fmt.Sprintf("(%s:%s {name: %s, namespace: %s, group: %s})", variable, r.Kind, quote(r.Metadata.Name), quote(r.Metadata.Namespace), quote(r.Group()))
+}
+
+// nodeProperties returns the properties set on a resource's node in addition to its identity.
+func nodeProperties(r *parser.Resource) map[string]interface{} {
+	properties := make(map[string]interface{}, len(r.Properties)+3)
+	if r.APIVersion != "" {
+		properties["apiVersion"] = r.APIVersion
+	}
+	if r.Source != "" {
+		properties["source"] = r.Source
+		properties["chart"] = r.ChartPath()
+	}
+	if hook := r.Hook(); hook != nil {
+		properties["hookPhases"] = hook.Phases
+		properties["hookWeight"] = hook.Weight
+		if len(hook.DeletePolicies) > 0 {
+			properties["hookDeletePolicies"] = hook.DeletePolicies
+		}
+	}
+	for k, v := range r.Properties {
+		properties[k] = v
+	}
+	return properties
+}
+
+// nodeLabels returns the labels a resource's node carries in addition to its kind.
+func nodeLabels(r *parser.Resource) []string {
+	var labels []string
+	if r.Hook() != nil {
+		labels = append(labels, "HelmHook")
+	}
+	return labels
+}
+
+// Comment renders each line as a Cypher line comment.
+func Comment(lines ...string) string {
+	var sb strings.Builder
+	for _, line := range lines {
+		for _, l := range strings.Split(line, "\n") {
+			sb.WriteString("// " + l + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// setClause renders labels and properties as a Cypher SET list on the given variable,
+// with properties in key order.
+func setClause(variable string, labels []string, properties map[string]interface{}) string {
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	assignments := make([]string, 0, len(labels)+len(keys))
+	for _, l := range labels {
+		assignments = append(assignments, fmt.Sprintf("%s:%s", variable, l))
+	}
+	for _, k := range keys {
+		assignments = append(assignments, fmt.Sprintf("%s.%s = %s", variable, k, literal(properties[k])))
+	}
+	return strings.Join(assignments, ", ")
+}
+
+// propertyMap renders properties as a Cypher map literal preceded by a space, in key order,
+// or "" when there are none.
+func propertyMap(properties map[string]interface{}) string {
+	if len(properties) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, fmt.Sprintf("%s: %s", k, literal(properties[k])))
+	}
+	return " {" + strings.Join(entries, ", ") + "}"
+}
+
+// literal renders a value as a Cypher literal. Neo4j properties can only hold primitives and
+// lists of primitives, so maps and other structured values are stored as JSON strings.
+func literal(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return quote(val)
+	case bool, int, int32, int64, float32, float64:
+		return fmt.Sprintf("%v", val)
+	case []string:
+		items := make([]string, len(val))
+		for i, s := range val {
+			items[i] = quote(s)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		encoded, err := json.Marshal(val)
+		if err != nil {
+			return quote(fmt.Sprintf("%v", val))
+		}
+		return quote(string(encoded))
+	}
+}
+
+// quote renders s as a single-quoted Cypher string.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
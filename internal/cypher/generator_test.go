@@ -35,11 +35,11 @@ func TestGenerate(t *testing.T) {
 
 	script := Generate(resources, relationships)
 
-	expectedConstraint1 := "CREATE CONSTRAINT IF NOT EXISTS FOR (n:Service) REQUIRE (n.name, n.namespace) IS UNIQUE;"
-	expectedConstraint2 := "CREATE CONSTRAINT IF NOT EXISTS FOR (n:Deployment) REQUIRE (n.name, n.namespace) IS UNIQUE;"
-	expectedNode1 := "MERGE (:Service {name: 'my-service', namespace: 'default', kind: 'Service'});"
-	expectedNode2 := "MERGE (:Deployment {name: 'my-deployment', namespace: 'default', kind: 'Deployment'});"
-	expectedRel := "MATCH (a:Service {name: 'my-service'}), (b:Deployment {name: 'my-deployment'}) MERGE (a)-[:SELECTS]->(b);"
+	expectedConstraint1 := "CREATE CONSTRAINT helmgraph_Service IF NOT EXISTS FOR (n:Service) REQUIRE (n.group, n.name, n.namespace) IS UNIQUE;"
+	expectedConstraint2 := "CREATE CONSTRAINT helmgraph_Deployment IF NOT EXISTS FOR (n:Deployment) REQUIRE (n.group, n.name, n.namespace) IS UNIQUE;"
+	expectedNode1 := "MERGE (:Service {name: 'my-service', namespace: 'default', group: '', kind: 'Service'});"
+	expectedNode2 := "MERGE (:Deployment {name: 'my-deployment', namespace: 'default', group: '', kind: 'Deployment'});"
+	expectedRel := "MATCH (a:Service {name: 'my-service', namespace: 'default', group: ''}), (b:Deployment {name: 'my-deployment', namespace: 'default', group: ''}) MERGE (a)-[:SELECTS]->(b);"
 
 	if !strings.Contains(script, expectedConstraint1) {
 		t.Errorf("script does not contain expected constraint: %s", expectedConstraint1)
@@ -57,3 +57,136 @@ func TestGenerate(t *testing.T) {
 		t.Errorf("script does not contain expected relationship: %s", expectedRel)
 	}
 }
+
+func TestGenerateNodeProperties(t *testing.T) {
+	resources := []*parser.Resource{
+		{
+			Kind: "Release",
+			Metadata: parser.Metadata{
+				Name:      "my-release",
+				Namespace: "default",
+			},
+			Properties: map[string]interface{}{
+				"chart":  "charts/it's-mine",
+				"values": []string{"chart:values.yaml", "set:replicas"},
+			},
+		},
+	}
+
+	script := Generate(resources, nil)
+
+	expectedNode := `MERGE (n:Release {name: 'my-release', namespace: 'default', group: '', kind: 'Release'}) SET n.chart = 'charts/it\'s-mine', n.values = ['chart:values.yaml', 'set:replicas'];`
+	if !strings.Contains(script, expectedNode) {
+		t.Errorf("script does not contain expected node: %s\ngot:\n%s", expectedNode, script)
+	}
+}
+
+func TestComment(t *testing.T) {
+	expected := "// warning: first\n// second line\n// info: third\n"
+	if got := Comment("warning: first\nsecond line", "info: third"); got != expected {
+		t.Errorf("expected %q, but got %q", expected, got)
+	}
+}
+
+func TestGenerateRelationshipEndpoints(t *testing.T) {
+	chart := &parser.Resource{
+		Kind:       "Chart",
+		Metadata:   parser.Metadata{Name: "db"},
+		Properties: map[string]interface{}{"path": "app/charts/db"},
+	}
+	resources := []*parser.Resource{
+		{
+			Kind:     "Secret",
+			Metadata: parser.Metadata{Name: "db-credentials", Namespace: "default"},
+			Source:   "app/charts/db/templates/secret.yaml",
+		},
+	}
+	relationships := []*relations.Relationship{
+		{Source: chart, Target: resources[0], Type: "RENDERS"},
+	}
+
+	script := Generate(resources, relationships)
+
+	expected := []string{
+		"CREATE CONSTRAINT helmgraph_Chart IF NOT EXISTS FOR (n:Chart) REQUIRE (n.group, n.name, n.namespace) IS UNIQUE;",
+		"MERGE (n:Chart {name: 'db', namespace: '', group: '', kind: 'Chart'}) SET n.path = 'app/charts/db';",
+		"MERGE (n:Secret {name: 'db-credentials', namespace: 'default', group: '', kind: 'Secret'}) SET n.chart = 'app/charts/db', n.source = 'app/charts/db/templates/secret.yaml';",
+	}
+	for _, e := range expected {
+		if !strings.Contains(script, e) {
+			t.Errorf("script does not contain expected statement: %s\ngot:\n%s", e, script)
+		}
+	}
+}
+
+func TestGenerateHooks(t *testing.T) {
+	resources := []*parser.Resource{
+		{
+			Kind: "Job",
+			Metadata: parser.Metadata{
+				Name:      "db-migrate",
+				Namespace: "default",
+				Annotations: map[string]string{
+					"helm.sh/hook":        "pre-install",
+					"helm.sh/hook-weight": "-5",
+				},
+			},
+		},
+		{Kind: "Release", Metadata: parser.Metadata{Name: "my-release", Namespace: "default"}},
+	}
+	relationships := []*relations.Relationship{
+		{Source: resources[0], Target: resources[1], Type: "PRECEDES", Properties: map[string]interface{}{"phase": "pre-install"}},
+	}
+
+	script := Generate(resources, relationships)
+
+	expected := []string{
+		"MERGE (n:Job {name: 'db-migrate', namespace: 'default', group: '', kind: 'Job'}) SET n:HelmHook, n.hookPhases = ['pre-install'], n.hookWeight = -5;",
+		"MATCH (a:Job {name: 'db-migrate', namespace: 'default', group: ''}), (b:Release {name: 'my-release', namespace: 'default', group: ''}) MERGE (a)-[:PRECEDES {phase: 'pre-install'}]->(b);",
+	}
+	for _, e := range expected {
+		if !strings.Contains(script, e) {
+			t.Errorf("script does not contain expected statement: %s\ngot:\n%s", e, script)
+		}
+	}
+}
+
+func TestGenerateAPIGroups(t *testing.T) {
+	resources := []*parser.Resource{
+		{
+			APIVersion: "cert-manager.io/v1",
+			Kind:       "Certificate",
+			Metadata:   parser.Metadata{Name: "tls", Namespace: "default"},
+		},
+		{
+			APIVersion: "example.com/v1alpha1",
+			Kind:       "Certificate",
+			Metadata:   parser.Metadata{Name: "tls", Namespace: "default"},
+		},
+	}
+	issuer := parser.NewPlaceholder(parser.Identity{Group: "cert-manager.io", Kind: "ClusterIssuer", Name: "letsencrypt"})
+	relationships := []*relations.Relationship{
+		{Source: resources[1], Target: resources[0], Type: "MIRRORS"},
+		{Source: resources[0], Target: issuer, Type: "ISSUED_BY"},
+	}
+
+	script := Generate(resources, relationships)
+
+	for _, expected := range []string{
+		"CREATE CONSTRAINT helmgraph_Certificate IF NOT EXISTS FOR (n:Certificate) REQUIRE (n.group, n.name, n.namespace) IS UNIQUE;",
+		"MERGE (n:Certificate {name: 'tls', namespace: 'default', group: 'cert-manager.io', kind: 'Certificate'}) SET n.apiVersion = 'cert-manager.io/v1';",
+		"MERGE (n:Certificate {name: 'tls', namespace: 'default', group: 'example.com', kind: 'Certificate'}) SET n.apiVersion = 'example.com/v1alpha1';",
+		"MATCH (a:Certificate {name: 'tls', namespace: 'default', group: 'example.com'}), (b:Certificate {name: 'tls', namespace: 'default', group: 'cert-manager.io'}) MERGE (a)-[:MIRRORS]->(b);",
+		"MERGE (n:ClusterIssuer {name: 'letsencrypt', namespace: '', group: 'cert-manager.io', kind: 'ClusterIssuer'}) SET n.placeholder = true;",
+	} {
+		if !strings.Contains(script, expected) {
+			t.Errorf("script does not contain expected statement: %s\ngot:\n%s", expected, script)
+		}
+	}
+	if n := strings.Count(script, "CREATE CONSTRAINT helmgraph_Certificate IF NOT EXISTS FOR (n:Certificate)"); n != 1 {
+		t.Errorf("expected 1 constraint for the Certificate label, but got %d", n)
+	}
+	if strings.Contains(script, "apiVersion = 'cert-manager.io/'") {
+		t.Errorf("expected no apiVersion on the placeholder ClusterIssuer\ngot:\n%s", script)
+	}
+}
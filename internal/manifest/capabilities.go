@@ -0,0 +1,91 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// Capabilities describes the cluster a chart is rendered for, as seen by templates through
+// .Capabilities. An empty KubeVersion selects Helm's default.
+//
+// ClusterAPIVersions lists every API version the target cluster serves and replaces Helm's
+// default set when it is not empty; APIVersions are added on top, like 'helm template --api-versions'.
+type Capabilities struct {
+	KubeVersion        string
+	ClusterAPIVersions []string
+	APIVersions        []string
+}
+
+// Profile is the file format describing a target cluster, e.g.
+//
+//	kubeVersion: v1.27.9
+//	apiVersions:
+//	  - v1
+//	  - apps/v1
+//	  - monitoring.coreos.com/v1/ServiceMonitor
+type Profile struct {
+	KubeVersion string   `yaml:"kubeVersion"`
+	APIVersions []string `yaml:"apiVersions"`
+}
+
+// LoadProfile reads a cluster profile file into Capabilities.
+func LoadProfile(path string) (Capabilities, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Capabilities{}, fmt.Errorf("failed to read cluster profile: %w", err)
+	}
+	var profile Profile
+	if err := yaml.Unmarshal(data, &profile); err != nil {
+		return Capabilities{}, fmt.Errorf("failed to parse cluster profile %s: %w", path, err)
+	}
+	return Capabilities{KubeVersion: profile.KubeVersion, ClusterAPIVersions: profile.APIVersions}, nil
+}
+
+// helmCapabilities converts the capabilities into the form used by the Helm template engine.
+func (c Capabilities) helmCapabilities() (*chartutil.Capabilities, error) {
+	caps := chartutil.DefaultCapabilities.Copy()
+	if c.KubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(c.KubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kube version %q: %w", c.KubeVersion, err)
+		}
+		caps.KubeVersion = *kubeVersion
+	}
+
+	var apiVersions chartutil.VersionSet
+	if len(c.ClusterAPIVersions) > 0 {
+		apiVersions = append(apiVersions, c.ClusterAPIVersions...)
+	} else {
+		apiVersions = append(apiVersions, caps.APIVersions...)
+	}
+	caps.APIVersions = append(apiVersions, c.APIVersions...)
+	return caps, nil
+}
+
+// Args returns the capabilities as 'helm template' command line arguments. The helm binary
+// cannot replace its default API versions, so cluster API versions are added to them instead.
+func (c Capabilities) Args() []string {
+	var args []string
+	if c.KubeVersion != "" {
+		args = append(args, "--kube-version", c.KubeVersion)
+	}
+	for _, v := range append(append([]string{}, c.ClusterAPIVersions...), c.APIVersions...) {
+		args = append(args, "--api-versions", v)
+	}
+	return args
+}
+
+// checkKubeVersion mirrors Helm's check of the chart's kubeVersion constraint.
+func checkKubeVersion(chrt *chart.Chart, caps *chartutil.Capabilities) error {
+	if chrt.Metadata.KubeVersion == "" {
+		return nil
+	}
+	if !chartutil.IsCompatibleRange(chrt.Metadata.KubeVersion, caps.KubeVersion.String()) {
+		return fmt.Errorf("chart requires kubeVersion: %s which is incompatible with Kubernetes %s", chrt.Metadata.KubeVersion, caps.KubeVersion.String())
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Severity classifies a Diagnostic.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// Diagnostic is a non-fatal message reported by Helm while rendering a chart, such as a
+// deprecation notice or a values coalescing warning. Source is the Helm source location
+// that reported it, when known.
+type Diagnostic struct {
+	Severity Severity
+	Source   string
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	if d.Source != "" {
+		return fmt.Sprintf("%s: %s: %s", d.Severity, d.Source, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Severity, d.Message)
+}
+
+// Result is the outcome of rendering a chart.
+type Result struct {
+	Manifest    string
+	Diagnostics []Diagnostic
+}
+
+// diagnosticSource matches the "file.go:123: " prefix Helm's logger puts on messages.
+var diagnosticSource = regexp.MustCompile(`^([\w.-]+\.go:\d+): `)
+
+// severityPrefixes maps the message prefixes Helm uses to a severity.
+var severityPrefixes = []struct {
+	prefix   string
+	severity Severity
+}{
+	{"[INFO] ", SeverityInfo},
+	{"info: ", SeverityInfo},
+	{"[ERROR] ", SeverityError},
+	{"WARNING: ", SeverityWarning},
+	{"Warning: ", SeverityWarning},
+	{"warning: ", SeverityWarning},
+}
+
+// parseDiagnostics turns Helm's stderr or log output into diagnostics, one per line.
+// Messages without a recognised severity are reported as warnings.
+func parseDiagnostics(output string) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		d := Diagnostic{Severity: SeverityWarning}
+		if matches := diagnosticSource.FindStringSubmatch(line); matches != nil {
+			d.Source = matches[1]
+			line = strings.TrimPrefix(line, matches[0])
+		}
+		for _, p := range severityPrefixes {
+			if strings.HasPrefix(line, p.prefix) {
+				d.Severity = p.severity
+				line = strings.TrimPrefix(line, p.prefix)
+				break
+			}
+		}
+		d.Message = line
+		diagnostics = append(diagnostics, d)
+	}
+	return diagnostics
+}
+
+// logMu serialises captureLog, since the standard logger Helm writes to is global.
+var logMu sync.Mutex
+
+// captureLog runs fn while collecting everything written to the standard logger.
+func captureLog(fn func() error) (string, error) {
+	logMu.Lock()
+	defer logMu.Unlock()
+
+	var buf bytes.Buffer
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(log.Lshortfile)
+	defer func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}()
+
+	err := fn()
+	return buf.String(), err
+}
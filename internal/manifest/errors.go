@@ -0,0 +1,41 @@
+package manifest
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// templateLocation matches the locations Helm reports in template errors, e.g.
+// "template: mychart/templates/svc.yaml:12:3:" or "parse error at (mychart/templates/svc.yaml:12):".
+var templateLocation = regexp.MustCompile(`(?:template: |\()([^\s():]+\.[A-Za-z0-9]+):(\d+)(?::(\d+))?(?::|\))`)
+
+// RenderError is returned when a chart template fails to render.
+// Template and Line are set when Helm reported where the failure happened.
+type RenderError struct {
+	Template string
+	Line     int
+	Column   int
+	Err      error
+}
+
+func (e *RenderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RenderError) Unwrap() error {
+	return e.Err
+}
+
+// newRenderError wraps err in a RenderError, extracting the template location from the message.
+func newRenderError(err error) *RenderError {
+	renderErr := &RenderError{Err: err}
+	matches := templateLocation.FindStringSubmatch(err.Error())
+	if len(matches) > 0 {
+		renderErr.Template = matches[1]
+		renderErr.Line, _ = strconv.Atoi(matches[2])
+		if matches[3] != "" {
+			renderErr.Column, _ = strconv.Atoi(matches[3])
+		}
+	}
+	return renderErr
+}
@@ -0,0 +1,69 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+)
+
+// ExecRenderer renders charts by running 'helm template' as a subprocess.
+type ExecRenderer struct{}
+
+// Render runs the 'helm template' command and returns its output. Anything helm writes
+// to stderr on success is returned as diagnostics.
+func (e *ExecRenderer) Render(opts Options) (*Result, error) {
+	chartPath := opts.ChartPath
+	if isRemote(opts) {
+		pulled, cleanup, err := fetchChart(opts, execDownload)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		chartPath = pulled
+	}
+
+	args := []string{"template"}
+	if opts.Namespace != "" {
+		args = append(args, "--namespace", opts.Namespace)
+	}
+
+	if opts.ReleaseName != "" {
+		args = append(args, "--release-name", opts.ReleaseName)
+	}
+
+	args = append(args, opts.Capabilities.Args()...)
+	args = append(args, opts.Values.Args()...)
+	args = append(args, chartPath)
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("helm", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, newRenderError(fmt.Errorf("failed to run helm template: %w\n%s", err, stderr.String()))
+	}
+
+	return &Result{Manifest: stdout.String(), Diagnostics: parseDiagnostics(stderr.String())}, nil
+}
+
+// execDownload downloads a chart from a repository or registry by running 'helm pull'.
+func execDownload(opts Options, dir string) error {
+	args := []string{"pull", opts.ChartPath, "--destination", dir}
+	if opts.Repo != "" {
+		args = append(args, "--repo", opts.Repo)
+	}
+	if opts.Version != "" {
+		args = append(args, "--version", opts.Version)
+	}
+	if opts.RegistryConfig != "" {
+		args = append(args, "--registry-config", opts.RegistryConfig)
+	}
+	if opts.PlainHTTP {
+		args = append(args, "--plain-http")
+	}
+	cmd := exec.Command("helm", args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to pull helm chart: %w\n%s", err, string(output))
+	}
+	return nil
+}
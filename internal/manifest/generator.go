@@ -1,38 +1,59 @@
 package manifest
 
-import (
-	"fmt"
-	"os/exec"
+import "fmt"
+
+// Mode selects how a chart is rendered.
+type Mode string
+
+const (
+	// ModeSDK renders the chart in-process with the Helm Go SDK.
+	ModeSDK Mode = "sdk"
+	// ModeExec renders the chart by running the 'helm' binary found on PATH.
+	ModeExec Mode = "exec"
 )
 
-// Generate runs the 'helm template' command and returns the output.
-func Generate(chartPath, releaseName, namespace, repo string) (string, error) {
-	if repo != "" {
-		// Fetch the chart from the repository
-		pullArgs := []string{"pull", chartPath, "--repo", repo, "--untar", "--destination", ".//.helm-charts"}
-		pullCmd := exec.Command("helm", pullArgs...)
-		if output, err := pullCmd.CombinedOutput(); err != nil {
-			return "", fmt.Errorf("failed to pull helm chart: %w\n%s", err, string(output))
-		}
-		chartPath = fmt.Sprintf(".//.helm-charts/%s", chartPath)
-	}
+// Options describes the chart to render and the release it is rendered for.
+// ChartPath is a local chart directory or archive, a chart name in Repo, or an
+// oci:// reference. Version constrains which chart version is pulled for remote charts,
+// which are kept in the chart cache at CacheDir (default: the user cache directory) unless NoCache is set.
+type Options struct {
+	ChartPath      string
+	ReleaseName    string
+	Namespace      string
+	Repo           string
+	Version        string
+	RegistryConfig string
+	PlainHTTP      bool
+	CacheDir       string
+	NoCache        bool
+	Mode           Mode
+	Values         ValueOptions
+	Capabilities   Capabilities
+}
 
-	args := []string{"template"}
-	if namespace != "" {
-		args = append(args, "--namespace", namespace)
-	}
+// Renderer renders a Helm chart into a multi-document Kubernetes manifest.
+// Warnings reported while rendering are returned as diagnostics rather than mixed into the manifest.
+type Renderer interface {
+	Render(opts Options) (*Result, error)
+}
 
-	if releaseName != "" {
-		args = append(args, "--release-name", releaseName)
+// NewRenderer returns the Renderer for the given mode. An empty mode selects the SDK renderer.
+func NewRenderer(mode Mode) (Renderer, error) {
+	switch mode {
+	case ModeSDK, "":
+		return &SDKRenderer{}, nil
+	case ModeExec:
+		return &ExecRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown render mode %q: expected %q or %q", mode, ModeSDK, ModeExec)
 	}
+}
 
-	args = append(args, chartPath)
-
-	cmd := exec.Command("helm", args...)
-	output, err := cmd.CombinedOutput()
+// Generate renders the chart described by opts and returns the manifest and any diagnostics.
+func Generate(opts Options) (*Result, error) {
+	renderer, err := NewRenderer(opts.Mode)
 	if err != nil {
-		return "", fmt.Errorf("failed to run helm template: %w\n%s", err, string(output))
+		return nil, err
 	}
-
-	return string(output), nil
+	return renderer.Render(opts)
 }
@@ -1,7 +1,9 @@
 package manifest
 
 import (
+	"errors"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -47,9 +49,9 @@ spec:
 	}
 
 	// Test case 1: Successful generation
-	output, err := Generate("testdata/mychart", "my-release", "default", "")
+	result, err := Generate(Options{ChartPath: "testdata/mychart", ReleaseName: "my-release", Namespace: "default"})
 	if err != nil {
-		t.Errorf("unexpected error: %v", err)
+		t.Fatalf("unexpected error: %v", err)
 	}
 
 	expectedOutput := `---
@@ -67,12 +69,12 @@ spec:
   selector:
     app: nginx
 `
-	if output != expectedOutput {
-		t.Errorf("unexpected output.\nGot:\n%s\nExpected:\n%s", output, expectedOutput)
+	if result.Manifest != expectedOutput {
+		t.Errorf("unexpected output.\nGot:\n%s\nExpected:\n%s", result.Manifest, expectedOutput)
 	}
 
 	// Test case 2: Chart not found
-	_, err = Generate("testdata/nonexistent-chart", "my-release", "default", "")
+	_, err = Generate(Options{ChartPath: "testdata/nonexistent-chart", ReleaseName: "my-release", Namespace: "default"})
 	if err == nil {
 		t.Error("expected an error, but got nil")
 	}
@@ -81,8 +83,287 @@ spec:
 	// This is an integration test and requires network access and helm CLI to be configured.
 	// It is disabled by default.
 	t.Skip("skipping remote chart test")
-	_, err = Generate("bitnami/nginx", "my-release", "default", "https://charts.bitnami.com/bitnami")
+	_, err = Generate(Options{ChartPath: "nginx", ReleaseName: "my-release", Namespace: "default", Repo: "https://charts.bitnami.com/bitnami"})
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
 }
+
+func TestGenerateRenderError(t *testing.T) {
+	err := os.MkdirAll("testdata/badchart/templates", 0755)
+	if err != nil {
+		t.Fatalf("failed to create test chart directory: %v", err)
+	}
+	defer os.RemoveAll("testdata")
+
+	chart := `apiVersion: v2
+name: badchart
+version: 0.1.0`
+	err = os.WriteFile("testdata/badchart/Chart.yaml", []byte(chart), 0644)
+	if err != nil {
+		t.Fatalf("failed to write test chart file: %v", err)
+	}
+
+	template := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+data:
+  key: {{ required "key is required" .Values.key }}
+`
+	err = os.WriteFile("testdata/badchart/templates/configmap.yaml", []byte(template), 0644)
+	if err != nil {
+		t.Fatalf("failed to write test template file: %v", err)
+	}
+
+	_, err = Generate(Options{ChartPath: "testdata/badchart", ReleaseName: "my-release", Namespace: "default"})
+	var renderErr *RenderError
+	if !errors.As(err, &renderErr) {
+		t.Fatalf("expected a RenderError, but got %v", err)
+	}
+	if renderErr.Template != "badchart/templates/configmap.yaml" {
+		t.Errorf("expected template badchart/templates/configmap.yaml, but got %q", renderErr.Template)
+	}
+	if renderErr.Line != 6 {
+		t.Errorf("expected line 6, but got %d", renderErr.Line)
+	}
+}
+
+func TestNewRenderer(t *testing.T) {
+	if r, err := NewRenderer(""); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if _, ok := r.(*SDKRenderer); !ok {
+		t.Errorf("expected the default renderer to be the SDK renderer, but got %T", r)
+	}
+
+	if r, err := NewRenderer(ModeExec); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	} else if _, ok := r.(*ExecRenderer); !ok {
+		t.Errorf("expected an exec renderer, but got %T", r)
+	}
+
+	if _, err := NewRenderer("kustomize"); err == nil {
+		t.Error("expected an error for an unknown mode, but got nil")
+	}
+}
+
+func TestGenerateWithValues(t *testing.T) {
+	err := os.MkdirAll("testdata/valueschart/templates", 0755)
+	if err != nil {
+		t.Fatalf("failed to create test chart directory: %v", err)
+	}
+	defer os.RemoveAll("testdata")
+
+	files := map[string]string{
+		"testdata/valueschart/Chart.yaml": `apiVersion: v2
+name: valueschart
+version: 0.1.0`,
+		"testdata/valueschart/values.yaml": `image: nginx
+tag: latest
+replicas: 1`,
+		"testdata/valueschart/templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+data:
+  image: {{ .Values.image }}:{{ .Values.tag }}
+  replicas: {{ .Values.replicas | quote }}
+`,
+		"testdata/prod.yaml": `tag: "1.25"
+replicas: 3`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := Generate(Options{
+		ChartPath:   "testdata/valueschart",
+		ReleaseName: "my-release",
+		Values: ValueOptions{
+			ValueFiles: []string{"testdata/prod.yaml"},
+			Values:     []string{"replicas=5"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result.Manifest, "image: nginx:1.25") {
+		t.Errorf("expected the values file to override the chart default, got:\n%s", result.Manifest)
+	}
+	if !strings.Contains(result.Manifest, `replicas: "5"`) {
+		t.Errorf("expected --set to override the values file, got:\n%s", result.Manifest)
+	}
+
+	expectedSources := []string{"chart:values.yaml", "values:testdata/prod.yaml", "set:replicas"}
+	sources := ValueOptions{ValueFiles: []string{"testdata/prod.yaml"}, Values: []string{"replicas=5"}}.Sources()
+	if strings.Join(sources, ",") != strings.Join(expectedSources, ",") {
+		t.Errorf("expected sources %v, but got %v", expectedSources, sources)
+	}
+}
+
+func TestGenerateDiagnostics(t *testing.T) {
+	err := os.MkdirAll("testdata/warnchart/templates", 0755)
+	if err != nil {
+		t.Fatalf("failed to create test chart directory: %v", err)
+	}
+	defer os.RemoveAll("testdata")
+
+	files := map[string]string{
+		"testdata/warnchart/Chart.yaml": `apiVersion: v2
+name: warnchart
+version: 0.1.0
+deprecated: true`,
+		"testdata/warnchart/values.yaml": `config: flat`,
+		"testdata/warnchart/templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	result, err := Generate(Options{
+		ChartPath:   "testdata/warnchart",
+		ReleaseName: "my-release",
+		Values:      ValueOptions{Values: []string{"config.key=value"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Contains(result.Manifest, "warning") || strings.Contains(result.Manifest, "deprecated") {
+		t.Errorf("expected warnings to be kept out of the manifest, got:\n%s", result.Manifest)
+	}
+	if len(result.Diagnostics) != 2 {
+		t.Fatalf("expected 2 diagnostics, but got %v", result.Diagnostics)
+	}
+	for _, d := range result.Diagnostics {
+		if d.Severity != SeverityWarning {
+			t.Errorf("expected a warning, but got %v", d)
+		}
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	stderr := `walk.go:75: found symbolic link in path: /charts/app/link resolves to /shared. Contents of linked file included and used
+WARNING: This chart is deprecated
+engine.go:206: [INFO] Missing required value: image.tag
+
+`
+	expected := []Diagnostic{
+		{Severity: SeverityWarning, Source: "walk.go:75", Message: "found symbolic link in path: /charts/app/link resolves to /shared. Contents of linked file included and used"},
+		{Severity: SeverityWarning, Message: "This chart is deprecated"},
+		{Severity: SeverityInfo, Source: "engine.go:206", Message: "Missing required value: image.tag"},
+	}
+
+	diagnostics := parseDiagnostics(stderr)
+	if len(diagnostics) != len(expected) {
+		t.Fatalf("expected %d diagnostics, but got %v", len(expected), diagnostics)
+	}
+	for i := range expected {
+		if diagnostics[i] != expected[i] {
+			t.Errorf("expected %+v, but got %+v", expected[i], diagnostics[i])
+		}
+	}
+}
+
+func TestGenerateCapabilities(t *testing.T) {
+	err := os.MkdirAll("testdata/capschart/templates", 0755)
+	if err != nil {
+		t.Fatalf("failed to create test chart directory: %v", err)
+	}
+	defer os.RemoveAll("testdata")
+
+	files := map[string]string{
+		"testdata/capschart/Chart.yaml": `apiVersion: v2
+name: capschart
+version: 0.1.0
+kubeVersion: ">=1.25.0-0"`,
+		"testdata/capschart/templates/pdb.yaml": `{{- if .Capabilities.APIVersions.Has "policy/v1/PodDisruptionBudget" }}
+apiVersion: policy/v1
+{{- else }}
+apiVersion: policy/v1beta1
+{{- end }}
+kind: PodDisruptionBudget
+metadata:
+  name: {{ .Release.Name }}
+  annotations:
+    kube-version: {{ .Capabilities.KubeVersion.Version | quote }}
+    has-monitoring: {{ .Capabilities.APIVersions.Has "monitoring.coreos.com/v1" | quote }}
+`,
+		"testdata/profile.yaml": `kubeVersion: v1.27.9
+apiVersions:
+  - v1
+  - apps/v1
+  - policy/v1/PodDisruptionBudget
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// Helm's default Kubernetes version does not satisfy the chart's kubeVersion.
+	_, err = Generate(Options{ChartPath: "testdata/capschart", ReleaseName: "my-release"})
+	if err == nil {
+		t.Error("expected an error for an incompatible kube version, but got nil")
+	}
+
+	capabilities, err := LoadProfile("testdata/profile.yaml")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	capabilities.APIVersions = []string{"monitoring.coreos.com/v1"}
+
+	result, err := Generate(Options{ChartPath: "testdata/capschart", ReleaseName: "my-release", Capabilities: capabilities})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{"apiVersion: policy/v1\n", `kube-version: "v1.27.9"`, `has-monitoring: "true"`} {
+		if !strings.Contains(result.Manifest, expected) {
+			t.Errorf("expected the manifest to contain %q, got:\n%s", expected, result.Manifest)
+		}
+	}
+
+	capabilities.ClusterAPIVersions = []string{"v1"}
+	result, err = Generate(Options{ChartPath: "testdata/capschart", ReleaseName: "my-release", Capabilities: capabilities})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Manifest, "apiVersion: policy/v1beta1") {
+		t.Errorf("expected cluster API versions to replace the defaults, got:\n%s", result.Manifest)
+	}
+}
+
+func TestValueOptionsSourcesRedactsOverrides(t *testing.T) {
+	opts := ValueOptions{
+		ValueFiles:   []string{"prod.yaml"},
+		Values:       []string{`db.password=s3cr3t,tags={a,b},name=a\,b`},
+		StringValues: []string{"token=abc=def"},
+		JSONValues:   []string{`auth={"user":"admin","pass":"x,y"}`},
+		FileValues:   []string{"ca=certs/ca.pem"},
+	}
+
+	expected := []string{
+		"chart:values.yaml",
+		"values:prod.yaml",
+		"set-json:auth",
+		"set:db.password",
+		"set:tags",
+		"set:name",
+		"set-string:token",
+		"set-file:ca",
+	}
+	sources := opts.Sources()
+	if strings.Join(sources, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected sources %v, but got %v", expected, sources)
+	}
+}
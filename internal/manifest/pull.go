@@ -0,0 +1,138 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/registry"
+
+	"helmgraph/internal/cache"
+)
+
+// downloadFunc downloads the chart archive described by opts into dir.
+type downloadFunc func(opts Options, dir string) error
+
+// isRemote reports whether the chart has to be pulled before it can be rendered.
+func isRemote(opts Options) bool {
+	return opts.Repo != "" || registry.IsOCI(opts.ChartPath)
+}
+
+// isExactVersion reports whether version pins a single chart version rather than a range.
+func isExactVersion(version string) bool {
+	if version == "" {
+		return false
+	}
+	_, err := semver.StrictNewVersion(strings.TrimPrefix(version, "v"))
+	return err == nil
+}
+
+// openCache returns the chart cache selected by opts.
+func openCache(opts Options) (*cache.Cache, error) {
+	if opts.CacheDir != "" {
+		return cache.New(opts.CacheDir), nil
+	}
+	dir, err := cache.DefaultDir()
+	if err != nil {
+		return nil, err
+	}
+	return cache.New(dir), nil
+}
+
+// fetchChart returns the path of the chart archive for a remote chart, downloading it unless an
+// exact version is already cached. The returned cleanup function must be called once the chart
+// has been rendered; until then, the cached archive is kept from being pruned.
+func fetchChart(opts Options, download downloadFunc) (string, func(), error) {
+	noop := func() {}
+
+	var c *cache.Cache
+	release := noop
+	if !opts.NoCache {
+		var err error
+		if c, err = openCache(opts); err != nil {
+			return "", noop, err
+		}
+		if release, err = c.Use(); err != nil {
+			return "", noop, err
+		}
+		if isExactVersion(opts.Version) {
+			e, ok, err := c.Lookup(opts.Repo, opts.ChartPath, strings.TrimPrefix(opts.Version, "v"))
+			if err != nil {
+				release()
+				return "", noop, err
+			}
+			if ok {
+				return e.Path, release, nil
+			}
+		}
+	}
+
+	tmpDir, err := os.MkdirTemp("", "helmgraph-chart-")
+	if err != nil {
+		release()
+		return "", noop, fmt.Errorf("failed to pull helm chart: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	if err := download(opts, tmpDir); err != nil {
+		cleanup()
+		release()
+		return "", noop, err
+	}
+	archives, err := filepath.Glob(filepath.Join(tmpDir, "*.tgz"))
+	if err != nil || len(archives) != 1 {
+		cleanup()
+		release()
+		return "", noop, fmt.Errorf("failed to pull helm chart: expected one chart archive, found %d", len(archives))
+	}
+
+	if c == nil {
+		return archives[0], cleanup, nil
+	}
+	defer cleanup()
+
+	chrt, err := loader.Load(archives[0])
+	if err != nil {
+		release()
+		return "", noop, fmt.Errorf("failed to load helm chart: %w", err)
+	}
+	e, err := c.Store(cache.Entry{Repo: opts.Repo, Chart: opts.ChartPath, Version: chrt.Metadata.Version}, archives[0])
+	if err != nil {
+		release()
+		return "", noop, err
+	}
+	return e.Path, release, nil
+}
+
+// sdkDownload downloads a chart from an HTTP repository or an OCI registry with the Helm SDK.
+func sdkDownload(opts Options, dir string) error {
+	settings := cli.New()
+	if opts.RegistryConfig != "" {
+		settings.RegistryConfig = opts.RegistryConfig
+	}
+
+	clientOpts := []registry.ClientOption{registry.ClientOptCredentialsFile(settings.RegistryConfig)}
+	if opts.PlainHTTP {
+		clientOpts = append(clientOpts, registry.ClientOptPlainHTTP())
+	}
+	registryClient, err := registry.NewClient(clientOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create registry client: %w", err)
+	}
+
+	pull := action.NewPullWithOpts(action.WithConfig(&action.Configuration{RegistryClient: registryClient}))
+	pull.Settings = settings
+	pull.RepoURL = opts.Repo
+	pull.Version = opts.Version
+	pull.PlainHTTP = opts.PlainHTTP
+	pull.DestDir = dir
+	if _, err := pull.Run(opts.ChartPath); err != nil {
+		return fmt.Errorf("failed to pull helm chart: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package manifest
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo/repotest"
+)
+
+func TestGenerateOCI(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := repotest.NewOCIServer(t, dir)
+	if err != nil {
+		t.Fatalf("failed to create OCI registry: %v", err)
+	}
+	go srv.ListenAndServe()
+	cacheDir := t.TempDir()
+	waitForRegistry(t, srv.RegistryURL)
+
+	credentialsFile := filepath.Join(dir, "config.json")
+	client, err := registry.NewClient(registry.ClientOptCredentialsFile(credentialsFile), registry.ClientOptPlainHTTP())
+	if err != nil {
+		t.Fatalf("failed to create registry client: %v", err)
+	}
+	err = client.Login(srv.RegistryURL,
+		registry.LoginOptBasicAuth(srv.TestUsername, srv.TestPassword),
+		registry.LoginOptInsecure(true),
+		registry.LoginOptPlainText(true))
+	if err != nil {
+		t.Fatalf("failed to log into registry: %v", err)
+	}
+
+	for _, version := range []string{"0.1.0", "0.2.0"} {
+		ch := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "ocichart", Version: version},
+			Templates: []*chart.File{{
+				Name: "templates/configmap.yaml",
+				Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .Release.Name }}-{{ .Chart.Version }}\n"),
+			}},
+		}
+		archive, err := chartutil.Save(ch, dir)
+		if err != nil {
+			t.Fatalf("failed to package chart: %v", err)
+		}
+		data, err := os.ReadFile(archive)
+		if err != nil {
+			t.Fatalf("failed to read chart archive: %v", err)
+		}
+		if _, err := client.Push(data, fmt.Sprintf("%s/charts/ocichart:%s", srv.RegistryURL, version)); err != nil {
+			t.Fatalf("failed to push chart: %v", err)
+		}
+	}
+
+	result, err := Generate(Options{
+		ChartPath:      fmt.Sprintf("oci://%s/charts/ocichart", srv.RegistryURL),
+		ReleaseName:    "my-release",
+		Version:        "~0.1.0",
+		RegistryConfig: credentialsFile,
+		PlainHTTP:      true,
+		CacheDir:       cacheDir,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Manifest, "name: my-release-0.1.0") {
+		t.Errorf("expected the pinned chart version to be rendered, got:\n%s", result.Manifest)
+	}
+
+	// A second run without credentials still renders the exact version from the cache.
+	result, err = Generate(Options{
+		ChartPath:   fmt.Sprintf("oci://%s/charts/ocichart", srv.RegistryURL),
+		ReleaseName: "my-release",
+		Version:     "0.1.0",
+		PlainHTTP:   true,
+		CacheDir:    cacheDir,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.Manifest, "name: my-release-0.1.0") {
+		t.Errorf("expected the cached chart version to be rendered, got:\n%s", result.Manifest)
+	}
+
+	_, err = Generate(Options{
+		ChartPath:   fmt.Sprintf("oci://%s/charts/ocichart", srv.RegistryURL),
+		ReleaseName: "my-release",
+		Version:     "0.2.0",
+		PlainHTTP:   true,
+		NoCache:     true,
+	})
+	if err == nil {
+		t.Error("expected an error without registry credentials, but got nil")
+	}
+}
+
+// waitForRegistry blocks until the registry accepts connections.
+func waitForRegistry(t *testing.T, addr string) {
+	for i := 0; i < 50; i++ {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("registry at %s did not start", addr)
+}
@@ -0,0 +1,84 @@
+package manifest
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Stdin is the path that selects standard input in Read.
+const Stdin = "-"
+
+// Read reads pre-rendered manifests from files, directory trees or stdin and joins them into a
+// single multi-document manifest. Directories are walked recursively and only .yaml, .yml and
+// .json files are read, in lexical order.
+func Read(paths []string, stdin io.Reader) (string, error) {
+	var documents []string
+	for _, p := range paths {
+		if p == Stdin {
+			data, err := io.ReadAll(stdin)
+			if err != nil {
+				return "", fmt.Errorf("failed to read manifest from stdin: %w", err)
+			}
+			documents = append(documents, string(data))
+			continue
+		}
+
+		files, err := manifestFiles(p)
+		if err != nil {
+			return "", err
+		}
+		for _, f := range files {
+			data, err := os.ReadFile(f)
+			if err != nil {
+				return "", fmt.Errorf("failed to read manifest: %w", err)
+			}
+			documents = append(documents, string(data))
+		}
+	}
+
+	var sb strings.Builder
+	for _, d := range documents {
+		sb.WriteString("---\n")
+		sb.WriteString(d)
+		if !strings.HasSuffix(d, "\n") {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String(), nil
+}
+
+// manifestFiles returns the manifest files at path, walking it if it is a directory.
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(p)) {
+		case ".yaml", ".yml", ".json":
+			files = append(files, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest directory %s: %w", path, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
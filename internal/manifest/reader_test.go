@@ -0,0 +1,68 @@
+package manifest
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRead(t *testing.T) {
+	err := os.MkdirAll("testdata/manifests/nested", 0755)
+	if err != nil {
+		t.Fatalf("failed to create test manifest directory: %v", err)
+	}
+	defer os.RemoveAll("testdata")
+
+	files := map[string]string{
+		"testdata/manifests/a-service.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: my-service`,
+		"testdata/manifests/nested/b-deployment.yml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-deployment
+`,
+		"testdata/manifests/README.md": `not a manifest`,
+		"testdata/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	stdin := strings.NewReader("apiVersion: v1\nkind: Secret\nmetadata:\n  name: my-secret\n")
+	output, err := Read([]string{"testdata/manifests", "testdata/configmap.yaml", Stdin}, stdin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectedOrder := []string{"name: my-service", "name: my-deployment", "name: my-configmap", "name: my-secret"}
+	last := -1
+	for _, expected := range expectedOrder {
+		i := strings.Index(output, expected)
+		if i < 0 {
+			t.Fatalf("output does not contain %q:\n%s", expected, output)
+		}
+		if i < last {
+			t.Errorf("expected %q to follow the previous manifests:\n%s", expected, output)
+		}
+		last = i
+	}
+	if strings.Contains(output, "not a manifest") {
+		t.Errorf("expected non-YAML files to be skipped:\n%s", output)
+	}
+	if strings.Count(output, "---\n") != 4 {
+		t.Errorf("expected 4 document separators, got:\n%s", output)
+	}
+
+	_, err = Read([]string{"testdata/missing.yaml"}, nil)
+	if err == nil {
+		t.Error("expected an error, but got nil")
+	}
+}
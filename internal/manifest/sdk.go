@@ -0,0 +1,123 @@
+package manifest
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// SDKRenderer renders charts in-process with the Helm chart loader and template engine.
+// It never contacts a cluster: capabilities are Helm's offline defaults.
+type SDKRenderer struct{}
+
+// Render loads the chart, renders its templates and returns them in 'helm template' format.
+// Messages Helm logs while doing so are returned as diagnostics.
+func (s *SDKRenderer) Render(opts Options) (*Result, error) {
+	var result *Result
+	logs, err := captureLog(func() error {
+		var err error
+		result, err = s.render(opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	result.Diagnostics = append(parseDiagnostics(logs), result.Diagnostics...)
+	return result, nil
+}
+
+func (s *SDKRenderer) render(opts Options) (*Result, error) {
+	chartPath := opts.ChartPath
+	if isRemote(opts) {
+		pulled, cleanup, err := fetchChart(opts, sdkDownload)
+		if err != nil {
+			return nil, err
+		}
+		defer cleanup()
+		chartPath = pulled
+	}
+
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load helm chart: %w", err)
+	}
+	if err := checkChartInstallable(chrt); err != nil {
+		return nil, err
+	}
+
+	userValues, err := opts.Values.Merge()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := chartutil.ProcessDependenciesWithMerge(chrt, userValues); err != nil {
+		return nil, fmt.Errorf("failed to process chart dependencies: %w", err)
+	}
+
+	caps, err := opts.Capabilities.helmCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKubeVersion(chrt, caps); err != nil {
+		return nil, err
+	}
+
+	releaseOptions := chartutil.ReleaseOptions{
+		Name:      opts.ReleaseName,
+		Namespace: opts.Namespace,
+		Revision:  1,
+		IsInstall: true,
+	}
+	values, err := chartutil.ToRenderValues(chrt, userValues, releaseOptions, caps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute chart values: %w", err)
+	}
+
+	files, err := engine.Render(chrt, values)
+	if err != nil {
+		return nil, newRenderError(err)
+	}
+
+	for name := range files {
+		if strings.HasSuffix(name, "NOTES.txt") {
+			delete(files, name)
+		}
+	}
+
+	hooks, manifests, err := releaseutil.SortManifests(files, caps.APIVersions, releaseutil.InstallOrder)
+	if err != nil {
+		return nil, newRenderError(err)
+	}
+
+	var b bytes.Buffer
+	for _, m := range manifests {
+		fmt.Fprintf(&b, "---\n# Source: %s\n%s\n", m.Name, m.Content)
+	}
+	for _, h := range hooks {
+		fmt.Fprintf(&b, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+
+	result := &Result{Manifest: b.String()}
+	if chrt.Metadata.Deprecated {
+		result.Diagnostics = append(result.Diagnostics, Diagnostic{Severity: SeverityWarning, Message: "This chart is deprecated"})
+	}
+	return result, nil
+}
+
+// checkChartInstallable mirrors Helm's check that only application charts can be rendered.
+func checkChartInstallable(chrt *chart.Chart) error {
+	if chrt.Metadata == nil {
+		return fmt.Errorf("chart %s has no metadata", chrt.Name())
+	}
+	switch chrt.Metadata.Type {
+	case "", "application":
+		return nil
+	}
+	return fmt.Errorf("%s charts are not installable", chrt.Metadata.Type)
+}
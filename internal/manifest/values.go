@@ -0,0 +1,128 @@
+package manifest
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// ValueOptions holds the user supplied values, in the same form as the 'helm template' flags.
+// They are merged in Helm's order: values files first, then --set-json, --set, --set-string and --set-file.
+type ValueOptions struct {
+	ValueFiles   []string
+	Values       []string
+	StringValues []string
+	FileValues   []string
+	JSONValues   []string
+}
+
+// Merge merges the values into a single map using Helm's merge order.
+func (v ValueOptions) Merge() (map[string]interface{}, error) {
+	opts := values.Options{
+		ValueFiles:   v.ValueFiles,
+		Values:       v.Values,
+		StringValues: v.StringValues,
+		FileValues:   v.FileValues,
+		JSONValues:   v.JSONValues,
+	}
+	merged, err := opts.MergeValues(getter.All(cli.New()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge values: %w", err)
+	}
+	return merged, nil
+}
+
+// Args returns the values as 'helm template' command line arguments.
+func (v ValueOptions) Args() []string {
+	var args []string
+	for _, f := range v.ValueFiles {
+		args = append(args, "--values", f)
+	}
+	for _, s := range v.Values {
+		args = append(args, "--set", s)
+	}
+	for _, s := range v.StringValues {
+		args = append(args, "--set-string", s)
+	}
+	for _, s := range v.FileValues {
+		args = append(args, "--set-file", s)
+	}
+	for _, s := range v.JSONValues {
+		args = append(args, "--set-json", s)
+	}
+	return args
+}
+
+// Sources describes where the values came from, in merge order, starting with the chart defaults.
+// Values files are listed by path; --set* overrides only by the keys they set, e.g. "set:db.password",
+// since their values are often credentials.
+func (v ValueOptions) Sources() []string {
+	sources := []string{"chart:values.yaml"}
+	for _, f := range v.ValueFiles {
+		sources = append(sources, "values:"+f)
+	}
+	for _, flag := range []struct {
+		name   string
+		values []string
+	}{
+		{"set-json", v.JSONValues},
+		{"set", v.Values},
+		{"set-string", v.StringValues},
+		{"set-file", v.FileValues},
+	} {
+		for _, s := range flag.values {
+			for _, key := range overrideKeys(s) {
+				sources = append(sources, flag.name+":"+key)
+			}
+		}
+	}
+	return sources
+}
+
+// overrideKeys returns the keys of a --set* flag value such as "a.b=1,c={x,y}", which assigns
+// values to comma-separated keys. Commas escaped with a backslash or nested in braces, brackets or
+// JSON strings do not separate assignments.
+func overrideKeys(s string) []string {
+	var keys []string
+	var key strings.Builder
+	inKey, depth, quoted := true, 0, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			if inKey {
+				key.WriteByte(s[i])
+			}
+			continue
+		case quoted:
+			quoted = c != '"'
+		case c == '"' && !inKey:
+			quoted = true
+		case (c == '{' || c == '[') && !inKey:
+			depth++
+		case (c == '}' || c == ']') && !inKey:
+			depth--
+		case c == '=' && inKey:
+			inKey = false
+			continue
+		case c == ',' && depth <= 0:
+			if key.Len() > 0 {
+				keys = append(keys, key.String())
+			}
+			key.Reset()
+			inKey, depth = true, 0
+			continue
+		}
+		if inKey {
+			key.WriteByte(c)
+		}
+	}
+	if key.Len() > 0 {
+		keys = append(keys, key.String())
+	}
+	return keys
+}
@@ -0,0 +1,199 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// KEDAGroup is the API group of KEDA's ScaledObjects, ScaledJobs and trigger authentications.
+const KEDAGroup = "keda.sh"
+
+// ScaleTargetRef refers to the workload an autoscaler scales.
+type ScaleTargetRef struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Name       string `yaml:"name"`
+}
+
+// Identity returns the identity of the scaled workload, defaulting its API version and kind
+// to the given ones.
+func (ref ScaleTargetRef) Identity(from *Resource, apiVersion, kind string) Identity {
+	if ref.APIVersion != "" {
+		apiVersion = ref.APIVersion
+	}
+	if ref.Kind != "" {
+		kind = ref.Kind
+	}
+	target := Resource{APIVersion: apiVersion}
+	return from.Reference(target.Group(), kind, ref.Name)
+}
+
+// MetricTarget is the target value of an autoscaling metric.
+type MetricTarget struct {
+	Type               string `yaml:"type"`
+	AverageUtilization int    `yaml:"averageUtilization"`
+	AverageValue       string `yaml:"averageValue"`
+	Value              string `yaml:"value"`
+}
+
+// String returns the target as e.g. "Utilization=80" or "AverageValue=100m".
+func (t MetricTarget) String() string {
+	switch {
+	case t.AverageUtilization != 0:
+		return fmt.Sprintf("Utilization=%d", t.AverageUtilization)
+	case t.AverageValue != "":
+		return "AverageValue=" + t.AverageValue
+	case t.Value != "":
+		return "Value=" + t.Value
+	}
+	return t.Type
+}
+
+// MetricSpec is an autoscaling/v2 HorizontalPodAutoscaler metric.
+type MetricSpec struct {
+	Type     string `yaml:"type"`
+	Resource struct {
+		Name   string       `yaml:"name"`
+		Target MetricTarget `yaml:"target"`
+	} `yaml:"resource"`
+	ContainerResource struct {
+		Name      string       `yaml:"name"`
+		Container string       `yaml:"container"`
+		Target    MetricTarget `yaml:"target"`
+	} `yaml:"containerResource"`
+	Pods struct {
+		Metric struct {
+			Name string `yaml:"name"`
+		} `yaml:"metric"`
+		Target MetricTarget `yaml:"target"`
+	} `yaml:"pods"`
+	Object struct {
+		Metric struct {
+			Name string `yaml:"name"`
+		} `yaml:"metric"`
+		Target MetricTarget `yaml:"target"`
+	} `yaml:"object"`
+	External struct {
+		Metric struct {
+			Name string `yaml:"name"`
+		} `yaml:"metric"`
+		Target MetricTarget `yaml:"target"`
+	} `yaml:"external"`
+}
+
+// String summarises the metric as "<type>/<name>:<target>", e.g. "Resource/cpu:Utilization=80".
+func (m MetricSpec) String() string {
+	var name string
+	var target MetricTarget
+	switch m.Type {
+	case "Resource":
+		name, target = m.Resource.Name, m.Resource.Target
+	case "ContainerResource":
+		name, target = m.ContainerResource.Container+"/"+m.ContainerResource.Name, m.ContainerResource.Target
+	case "Pods":
+		name, target = m.Pods.Metric.Name, m.Pods.Target
+	case "Object":
+		name, target = m.Object.Metric.Name, m.Object.Target
+	case "External":
+		name, target = m.External.Metric.Name, m.External.Target
+	}
+	return strings.TrimSuffix(fmt.Sprintf("%s/%s:%s", m.Type, name, target), ":")
+}
+
+// HorizontalPodAutoscalerSpec is the spec of an autoscaling/v1 or v2 HorizontalPodAutoscaler.
+type HorizontalPodAutoscalerSpec struct {
+	ScaleTargetRef                 ScaleTargetRef `yaml:"scaleTargetRef"`
+	MinReplicas                    *int           `yaml:"minReplicas"`
+	MaxReplicas                    int            `yaml:"maxReplicas"`
+	Metrics                        []MetricSpec   `yaml:"metrics"`
+	TargetCPUUtilizationPercentage *int           `yaml:"targetCPUUtilizationPercentage"`
+}
+
+// MetricNames summarises the metrics the autoscaler scales on. The autoscaling/v1
+// targetCPUUtilizationPercentage is reported as a Resource metric.
+func (s *HorizontalPodAutoscalerSpec) MetricNames() []string {
+	metrics := make([]string, 0, len(s.Metrics)+1)
+	if s.TargetCPUUtilizationPercentage != nil {
+		metrics = append(metrics, fmt.Sprintf("Resource/cpu:Utilization=%d", *s.TargetCPUUtilizationPercentage))
+	}
+	for _, m := range s.Metrics {
+		metrics = append(metrics, m.String())
+	}
+	return metrics
+}
+
+// ScaleTrigger is a KEDA trigger, optionally authenticated through a TriggerAuthentication or
+// ClusterTriggerAuthentication.
+type ScaleTrigger struct {
+	Type              string `yaml:"type"`
+	Name              string `yaml:"name"`
+	AuthenticationRef *struct {
+		Name string `yaml:"name"`
+		Kind string `yaml:"kind"`
+	} `yaml:"authenticationRef"`
+}
+
+// String returns the trigger type, followed by its name if it has one.
+func (t ScaleTrigger) String() string {
+	if t.Name != "" {
+		return t.Type + "/" + t.Name
+	}
+	return t.Type
+}
+
+// ScaledObjectSpec is the spec of a KEDA ScaledObject or ScaledJob. ScaleTargetRef is only set
+// for ScaledObjects; ScaledJobs run their own Jobs.
+type ScaledObjectSpec struct {
+	ScaleTargetRef  ScaleTargetRef `yaml:"scaleTargetRef"`
+	MinReplicaCount *int           `yaml:"minReplicaCount"`
+	MaxReplicaCount *int           `yaml:"maxReplicaCount"`
+	Triggers        []ScaleTrigger `yaml:"triggers"`
+}
+
+// TriggerAuthenticationSpec is the spec of a KEDA TriggerAuthentication or ClusterTriggerAuthentication.
+type TriggerAuthenticationSpec struct {
+	SecretTargetRef []struct {
+		Parameter string `yaml:"parameter"`
+		Name      string `yaml:"name"`
+		Key       string `yaml:"key"`
+	} `yaml:"secretTargetRef"`
+}
+
+// HorizontalPodAutoscalerSpec returns the spec of a HorizontalPodAutoscaler, or false if the
+// resource is not one or its spec cannot be decoded.
+func (r *Resource) HorizontalPodAutoscalerSpec() (*HorizontalPodAutoscalerSpec, bool) {
+	if r.Group() != "autoscaling" || r.Kind != "HorizontalPodAutoscaler" {
+		return nil, false
+	}
+	var spec HorizontalPodAutoscalerSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
+
+// ScaledObjectSpec returns the spec of a KEDA ScaledObject or ScaledJob, or false if the
+// resource is neither or its spec cannot be decoded.
+func (r *Resource) ScaledObjectSpec() (*ScaledObjectSpec, bool) {
+	if r.Group() != KEDAGroup || (r.Kind != "ScaledObject" && r.Kind != "ScaledJob") {
+		return nil, false
+	}
+	var spec ScaledObjectSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
+
+// TriggerAuthenticationSpec returns the spec of a KEDA TriggerAuthentication or
+// ClusterTriggerAuthentication, or false if the resource is neither or its spec cannot be decoded.
+func (r *Resource) TriggerAuthenticationSpec() (*TriggerAuthenticationSpec, bool) {
+	if r.Group() != KEDAGroup || (r.Kind != "TriggerAuthentication" && r.Kind != "ClusterTriggerAuthentication") {
+		return nil, false
+	}
+	var spec TriggerAuthenticationSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
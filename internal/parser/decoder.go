@@ -2,33 +2,132 @@ package parser
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
-// Selector is a custom type to handle the different structures of Kubernetes selectors.
-// It can be a simple map[string]string (for Services) or a structured
-// map with matchLabels (for Deployments, StatefulSets, etc.). It normalizes both
-// formats into a simple map[string]string.
-type Selector map[string]string
+// Label selector operators of matchExpressions requirements.
+const (
+	SelectorOpIn           = "In"
+	SelectorOpNotIn        = "NotIn"
+	SelectorOpExists       = "Exists"
+	SelectorOpDoesNotExist = "DoesNotExist"
+)
+
+// LabelSelectorRequirement is a set-based selector requirement from matchExpressions.
+type LabelSelectorRequirement struct {
+	Key      string   `yaml:"key" json:"key"`
+	Operator string   `yaml:"operator" json:"operator"`
+	Values   []string `yaml:"values" json:"values,omitempty"`
+}
+
+// LabelSelector is a Kubernetes label selector. It handles the different structures of
+// Kubernetes selectors: a simple map[string]string (for Services and ReplicationControllers)
+// is decoded as MatchLabels, while a structured selector (for Deployments, PodDisruptionBudgets,
+// NetworkPolicies, affinity terms, etc.) may also carry matchExpressions.
+type LabelSelector struct {
+	MatchLabels      map[string]string          `yaml:"matchLabels" json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `yaml:"matchExpressions" json:"matchExpressions,omitempty"`
+}
 
 // UnmarshalYAML implements the yaml.Unmarshaler interface to handle multiple selector formats.
-func (s *Selector) UnmarshalYAML(node *yaml.Node) error {
-	// Case 1: Try to decode as a simple map[string]string (e.g., for a Service).
+func (s *LabelSelector) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to unmarshal selector: expected a map[string]string or a struct with matchLabels or matchExpressions")
+	}
+
+	// Case 1: a structured selector with matchLabels and/or matchExpressions (e.g., for a Deployment).
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i].Value; key == "matchLabels" || key == "matchExpressions" {
+			type plain LabelSelector
+			return node.Decode((*plain)(s))
+		}
+	}
+
+	// Case 2: a simple map[string]string (e.g., for a Service).
 	var simpleSelector map[string]string
-	if err := node.Decode(&simpleSelector); err == nil {
-		*s = simpleSelector
-		return nil
+	if err := node.Decode(&simpleSelector); err != nil {
+		return fmt.Errorf("failed to unmarshal selector: %w", err)
+	}
+	*s = LabelSelector{MatchLabels: simpleSelector}
+	return nil
+}
+
+// Empty reports whether the selector has neither matchLabels nor matchExpressions.
+func (s LabelSelector) Empty() bool {
+	return len(s.MatchLabels) == 0 && len(s.MatchExpressions) == 0
+}
+
+// Matches reports whether labels satisfy every matchLabels entry and matchExpressions
+// requirement of the selector. As in Kubernetes, an empty selector matches everything and a
+// requirement with an unknown operator matches nothing.
+func (s LabelSelector) Matches(labels map[string]string) bool {
+	for k, v := range s.MatchLabels {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
 	}
+	for _, r := range s.MatchExpressions {
+		if !r.Matches(labels) {
+			return false
+		}
+	}
+	return true
+}
 
-	// Case 2: Try to decode as a structured map with matchLabels (e.g., for a Deployment).
-	var structuredSelector struct {
-		MatchLabels map[string]string `yaml:"matchLabels"`
+// Matches reports whether labels satisfy the requirement.
+func (r LabelSelectorRequirement) Matches(labels map[string]string) bool {
+	value, ok := labels[r.Key]
+	switch r.Operator {
+	case SelectorOpIn:
+		return ok && contains(r.Values, value)
+	case SelectorOpNotIn:
+		return !ok || !contains(r.Values, value)
+	case SelectorOpExists:
+		return ok
+	case SelectorOpDoesNotExist:
+		return !ok
+	default:
+		return false
 	}
-	if err := node.Decode(&structuredSelector); err == nil {
-		*s = structuredSelector.MatchLabels
-		return nil
+}
+
+// String returns the selector in the syntax of 'kubectl --selector', e.g.
+// "app=web,tier in (backend,cache),!legacy".
+func (s LabelSelector) String() string {
+	var parts []string
+	keys := make([]string, 0, len(s.MatchLabels))
+	for k := range s.MatchLabels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+s.MatchLabels[k])
+	}
+	for _, r := range s.MatchExpressions {
+		switch r.Operator {
+		case SelectorOpIn:
+			parts = append(parts, fmt.Sprintf("%s in (%s)", r.Key, strings.Join(r.Values, ",")))
+		case SelectorOpNotIn:
+			parts = append(parts, fmt.Sprintf("%s notin (%s)", r.Key, strings.Join(r.Values, ",")))
+		case SelectorOpExists:
+			parts = append(parts, r.Key)
+		case SelectorOpDoesNotExist:
+			parts = append(parts, "!"+r.Key)
+		default:
+			parts = append(parts, fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(r.Values, ",")))
+		}
 	}
+	return strings.Join(parts, ",")
+}
 
-	return fmt.Errorf("failed to unmarshal selector: expected a map[string]string or a struct with matchLabels")
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
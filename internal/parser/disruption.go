@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// PodDisruptionBudgetSpec is the spec of a PodDisruptionBudget. MinAvailable and MaxUnavailable
+// are an absolute number or a percentage such as "50%".
+type PodDisruptionBudgetSpec struct {
+	MinAvailable   *string        `yaml:"minAvailable"`
+	MaxUnavailable *string        `yaml:"maxUnavailable"`
+	Selector       *LabelSelector `yaml:"selector"`
+	// emptySelectsAll is set for policy/v1, where an empty selector selects every pod.
+	emptySelectsAll bool
+}
+
+// PodDisruptionBudgetSpec returns the spec of a PodDisruptionBudget, or false if the resource is
+// not one or its spec cannot be decoded.
+func (r *Resource) PodDisruptionBudgetSpec() (*PodDisruptionBudgetSpec, bool) {
+	if r.Group() != "policy" || r.Kind != "PodDisruptionBudget" {
+		return nil, false
+	}
+	var spec PodDisruptionBudgetSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	spec.emptySelectsAll = r.Version() != "v1beta1"
+	return &spec, true
+}
+
+// Selects reports whether the budget selects pods with the given labels. A missing selector
+// selects no pods; an empty one selects every pod in the namespace with policy/v1 but none with
+// policy/v1beta1.
+func (s *PodDisruptionBudgetSpec) Selects(labels map[string]string) bool {
+	if s.Selector == nil {
+		return false
+	}
+	if s.Selector.Empty() {
+		return s.emptySelectsAll
+	}
+	return s.Selector.Matches(labels)
+}
+
+// AllowedDisruptions returns how many of replicas pods the budget allows to be evicted at once,
+// rounding percentages up as the disruption controller does. A budget with neither field set
+// allows every pod to be evicted.
+func (s *PodDisruptionBudgetSpec) AllowedDisruptions(replicas int) int {
+	switch {
+	case s.MinAvailable != nil:
+		allowed := replicas - scaledValue(*s.MinAvailable, replicas)
+		if allowed < 0 {
+			return 0
+		}
+		return allowed
+	case s.MaxUnavailable != nil:
+		return scaledValue(*s.MaxUnavailable, replicas)
+	}
+	return replicas
+}
+
+// scaledValue resolves an int-or-percent value against total, rounding percentages up.
+func scaledValue(value string, total int) int {
+	if p, ok := strings.CutSuffix(value, "%"); ok {
+		percent, err := strconv.Atoi(p)
+		if err != nil {
+			return 0
+		}
+		return int(math.Ceil(float64(percent) * float64(total) / 100))
+	}
+	n, _ := strconv.Atoi(value)
+	return n
+}
+
+// Replicas returns the number of replicas of a workload, defaulting to 1 as Kubernetes does.
+func (r *Resource) Replicas() int {
+	if n, ok := r.IntField("spec", "replicas"); ok {
+		return int(n)
+	}
+	return 1
+}
@@ -0,0 +1,173 @@
+package parser
+
+// GatewayGroup is the API group of the Gateway API.
+const GatewayGroup = "gateway.networking.k8s.io"
+
+// gatewayRouteKinds are the Gateway API route kinds.
+var gatewayRouteKinds = map[string]bool{
+	"HTTPRoute": true,
+	"GRPCRoute": true,
+	"TCPRoute":  true,
+	"TLSRoute":  true,
+	"UDPRoute":  true,
+}
+
+// ObjectReference is a Gateway API reference to a resource. Group, Kind and Namespace are
+// optional and default according to the kind of reference.
+type ObjectReference struct {
+	Group     *string `yaml:"group"`
+	Kind      string  `yaml:"kind"`
+	Namespace string  `yaml:"namespace"`
+	Name      string  `yaml:"name"`
+}
+
+// Identity returns the identity of the referenced resource, defaulting the group and kind to
+// the given ones and the namespace to that of from.
+func (ref ObjectReference) Identity(from *Resource, group, kind string) Identity {
+	if ref.Group != nil {
+		group = *ref.Group
+	}
+	if ref.Kind != "" {
+		kind = ref.Kind
+	}
+	id := from.Reference(group, kind, ref.Name)
+	if ref.Namespace != "" && !IsClusterScoped(group, kind) {
+		id.Namespace = ref.Namespace
+	}
+	return id
+}
+
+// ParentReference refers to the Gateway (or other parent) a route attaches to.
+type ParentReference struct {
+	ObjectReference `yaml:",inline"`
+	SectionName     string `yaml:"sectionName"`
+	Port            int    `yaml:"port"`
+}
+
+// BackendRef refers to the Service (or other backend) a route forwards to. A missing weight
+// defaults to 1.
+type BackendRef struct {
+	ObjectReference `yaml:",inline"`
+	Port            int  `yaml:"port"`
+	Weight          *int `yaml:"weight"`
+}
+
+// RouteRule is a rule of a route with the backends matching traffic is forwarded to.
+type RouteRule struct {
+	BackendRefs []BackendRef `yaml:"backendRefs"`
+}
+
+// RouteSpec is the spec shared by the Gateway API route kinds.
+type RouteSpec struct {
+	ParentRefs []ParentReference `yaml:"parentRefs"`
+	Hostnames  []string          `yaml:"hostnames"`
+	Rules      []RouteRule       `yaml:"rules"`
+}
+
+// GatewayListener is a listener of a Gateway.
+type GatewayListener struct {
+	Name     string `yaml:"name"`
+	Hostname string `yaml:"hostname"`
+	Port     int    `yaml:"port"`
+	Protocol string `yaml:"protocol"`
+	TLS      struct {
+		CertificateRefs []ObjectReference `yaml:"certificateRefs"`
+	} `yaml:"tls"`
+	AllowedRoutes struct {
+		Namespaces struct {
+			From     string         `yaml:"from"`
+			Selector *LabelSelector `yaml:"selector"`
+		} `yaml:"namespaces"`
+	} `yaml:"allowedRoutes"`
+}
+
+// GatewaySpec is the spec of a Gateway.
+type GatewaySpec struct {
+	GatewayClassName string            `yaml:"gatewayClassName"`
+	Listeners        []GatewayListener `yaml:"listeners"`
+}
+
+// ReferenceGrantFrom describes the resources that may refer to resources in the namespace of a ReferenceGrant.
+type ReferenceGrantFrom struct {
+	Group     string `yaml:"group"`
+	Kind      string `yaml:"kind"`
+	Namespace string `yaml:"namespace"`
+}
+
+// ReferenceGrantTo describes the resources in the namespace of a ReferenceGrant that may be
+// referred to. An empty name permits every resource of the kind.
+type ReferenceGrantTo struct {
+	Group string `yaml:"group"`
+	Kind  string `yaml:"kind"`
+	Name  string `yaml:"name"`
+}
+
+// ReferenceGrantSpec is the spec of a ReferenceGrant.
+type ReferenceGrantSpec struct {
+	From []ReferenceGrantFrom `yaml:"from"`
+	To   []ReferenceGrantTo   `yaml:"to"`
+}
+
+// IsGatewayRoute reports whether the resource is a Gateway API route.
+func (r *Resource) IsGatewayRoute() bool {
+	return r.Group() == GatewayGroup && gatewayRouteKinds[r.Kind]
+}
+
+// RouteSpec returns the spec of a Gateway API route, or false if the resource is not a route
+// or its spec cannot be decoded.
+func (r *Resource) RouteSpec() (*RouteSpec, bool) {
+	if !r.IsGatewayRoute() {
+		return nil, false
+	}
+	var spec RouteSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
+
+// GatewaySpec returns the spec of a Gateway, or false if the resource is not a Gateway or its
+// spec cannot be decoded.
+func (r *Resource) GatewaySpec() (*GatewaySpec, bool) {
+	if r.Group() != GatewayGroup || r.Kind != "Gateway" {
+		return nil, false
+	}
+	var spec GatewaySpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
+
+// ReferenceGrantSpec returns the spec of a ReferenceGrant, or false if the resource is not a
+// ReferenceGrant or its spec cannot be decoded.
+func (r *Resource) ReferenceGrantSpec() (*ReferenceGrantSpec, bool) {
+	if r.Group() != GatewayGroup || r.Kind != "ReferenceGrant" {
+		return nil, false
+	}
+	var spec ReferenceGrantSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
+
+// Permits reports whether the ReferenceGrant spec allows from to refer to the resource identified by to.
+func (s *ReferenceGrantSpec) Permits(from *Resource, to Identity) bool {
+	fromAllowed := false
+	for _, f := range s.From {
+		if f.Group == from.Group() && f.Kind == from.Kind && f.Namespace == from.Metadata.Namespace {
+			fromAllowed = true
+			break
+		}
+	}
+	if !fromAllowed {
+		return false
+	}
+	for _, t := range s.To {
+		if t.Group == to.Group && t.Kind == to.Kind && (t.Name == "" || t.Name == to.Name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Helm hook annotations.
+const (
+	HookAnnotation             = "helm.sh/hook"
+	HookWeightAnnotation       = "helm.sh/hook-weight"
+	HookDeletePolicyAnnotation = "helm.sh/hook-delete-policy"
+)
+
+// Hook describes how Helm runs a hook resource.
+type Hook struct {
+	// Phases are the lifecycle events the hook runs on, e.g. "pre-install" or "post-upgrade".
+	Phases         []string
+	Weight         int
+	DeletePolicies []string
+}
+
+// Hook returns the hook definition of the resource, or nil if it is not a Helm hook.
+func (r *Resource) Hook() *Hook {
+	phases := splitAnnotation(r.Metadata.Annotations[HookAnnotation])
+	if len(phases) == 0 {
+		return nil
+	}
+	hook := &Hook{
+		Phases:         phases,
+		DeletePolicies: splitAnnotation(r.Metadata.Annotations[HookDeletePolicyAnnotation]),
+	}
+	// Helm treats a missing or malformed weight as 0.
+	hook.Weight, _ = strconv.Atoi(strings.TrimSpace(r.Metadata.Annotations[HookWeightAnnotation]))
+	return hook
+}
+
+// HasPhase reports whether the hook runs on the given lifecycle event.
+func (h *Hook) HasPhase(phase string) bool {
+	for _, p := range h.Phases {
+		if p == phase {
+			return true
+		}
+	}
+	return false
+}
+
+// splitAnnotation splits a comma separated annotation value, dropping empty items.
+func splitAnnotation(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultNamespace is the namespace of namespaced resources when neither the manifest nor the
+// release specify one.
+const DefaultNamespace = "default"
+
+// Identity identifies a resource within a cluster. Namespace is empty for cluster-scoped resources.
+type Identity struct {
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// String returns the identity as "<kind>.<group>/<namespace>/<name>", leaving out the group of
+// core resources and the namespace of cluster-scoped ones.
+func (id Identity) String() string {
+	kind := id.Kind
+	if id.Group != "" {
+		kind += "." + id.Group
+	}
+	if id.Namespace == "" {
+		return fmt.Sprintf("%s/%s", kind, id.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", kind, id.Namespace, id.Name)
+}
+
+// clusterScoped lists the well-known cluster-scoped kinds by API group and kind.
+var clusterScoped = map[[2]string]bool{
+	{"", "Namespace"}:                                                    true,
+	{"", "Node"}:                                                         true,
+	{"", "PersistentVolume"}:                                             true,
+	{"", "ComponentStatus"}:                                              true,
+	{"rbac.authorization.k8s.io", "ClusterRole"}:                         true,
+	{"rbac.authorization.k8s.io", "ClusterRoleBinding"}:                  true,
+	{"storage.k8s.io", "StorageClass"}:                                   true,
+	{"storage.k8s.io", "CSIDriver"}:                                      true,
+	{"storage.k8s.io", "CSINode"}:                                        true,
+	{"storage.k8s.io", "VolumeAttachment"}:                               true,
+	{"apiextensions.k8s.io", "CustomResourceDefinition"}:                 true,
+	{"admissionregistration.k8s.io", "MutatingWebhookConfiguration"}:     true,
+	{"admissionregistration.k8s.io", "ValidatingWebhookConfiguration"}:   true,
+	{"admissionregistration.k8s.io", "ValidatingAdmissionPolicy"}:        true,
+	{"admissionregistration.k8s.io", "ValidatingAdmissionPolicyBinding"}: true,
+	{"apiregistration.k8s.io", "APIService"}:                             true,
+	{"certificates.k8s.io", "CertificateSigningRequest"}:                 true,
+	{"flowcontrol.apiserver.k8s.io", "FlowSchema"}:                       true,
+	{"flowcontrol.apiserver.k8s.io", "PriorityLevelConfiguration"}:       true,
+	{"networking.k8s.io", "IngressClass"}:                                true,
+	{"node.k8s.io", "RuntimeClass"}:                                      true,
+	{"policy", "PodSecurityPolicy"}:                                      true,
+	{"scheduling.k8s.io", "PriorityClass"}:                               true,
+	{"gateway.networking.k8s.io", "GatewayClass"}:                        true,
+	{"keda.sh", "ClusterTriggerAuthentication"}:                          true,
+	{"cert-manager.io", "ClusterIssuer"}:                                 true,
+}
+
+// IsClusterScoped reports whether resources of the given API group and kind are cluster-scoped.
+// Kinds not known to be cluster-scoped, including custom resources, are treated as namespaced.
+func IsClusterScoped(group, kind string) bool {
+	return clusterScoped[[2]string{group, kind}]
+}
+
+// Group returns the API group of the resource, e.g. "apps" for "apps/v1" and "" for the core
+// group's "v1". For a resource without an API version, such as a placeholder, it is APIGroup.
+func (r *Resource) Group() string {
+	if r.APIVersion == "" {
+		return r.APIGroup
+	}
+	if i := strings.Index(r.APIVersion, "/"); i >= 0 {
+		return r.APIVersion[:i]
+	}
+	return ""
+}
+
+// Version returns the API version of the resource without its group, e.g. "v1" for "apps/v1".
+func (r *Resource) Version() string {
+	return r.APIVersion[strings.Index(r.APIVersion, "/")+1:]
+}
+
+// Identity returns the identity of the resource.
+func (r *Resource) Identity() Identity {
+	return Identity{
+		Group:     r.Group(),
+		Kind:      r.Kind,
+		Namespace: r.Metadata.Namespace,
+		Name:      r.Metadata.Name,
+	}
+}
+
+// IsClusterScoped reports whether the resource is cluster-scoped.
+func (r *Resource) IsClusterScoped() bool {
+	return IsClusterScoped(r.Group(), r.Kind)
+}
+
+// Reference returns the identity of the resource of the given group and kind that r refers to
+// by name. Like Kubernetes, it resolves the name in r's namespace unless the kind is cluster-scoped.
+func (r *Resource) Reference(group, kind, name string) Identity {
+	id := Identity{Group: group, Kind: kind, Name: name}
+	if !IsClusterScoped(group, kind) {
+		id.Namespace = r.Metadata.Namespace
+	}
+	return id
+}
+
+// ResolveNamespaces sets the namespace of namespaced resources that do not specify one to the
+// release namespace, or DefaultNamespace if that is empty, and clears the namespace of
+// cluster-scoped resources, which Kubernetes ignores.
+func ResolveNamespaces(resources []*Resource, namespace string) {
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	for _, r := range resources {
+		switch {
+		case r.IsClusterScoped():
+			r.Metadata.Namespace = ""
+		case r.Metadata.Namespace == "":
+			r.Metadata.Namespace = namespace
+		}
+	}
+}
+
+// NewPlaceholder returns a resource standing in for a referenced resource that is not part of
+// the manifest, such as one created outside the chart. Its node is marked with the placeholder
+// property; its API version is unknown.
+func NewPlaceholder(id Identity) *Resource {
+	return &Resource{
+		Kind:     id.Kind,
+		APIGroup: id.Group,
+		Metadata: Metadata{
+			Name:      id.Name,
+			Namespace: id.Namespace,
+		},
+		Properties: map[string]interface{}{
+			"placeholder": true,
+		},
+	}
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// DockerHubRegistry is the registry of images that do not name one, such as "nginx:1.27".
+const DockerHubRegistry = "docker.io"
+
+// Types of the Secrets holding image registry credentials, and the keys of their data.
+const (
+	SecretTypeDockerConfigJSON = "kubernetes.io/dockerconfigjson"
+	SecretTypeDockercfg        = "kubernetes.io/dockercfg"
+	dockerConfigJSONKey        = ".dockerconfigjson"
+	dockercfgKey               = ".dockercfg"
+)
+
+// ImageRegistry returns the registry host of a container image reference. As in Docker, the
+// first path component is the registry only if it contains a "." or ":" or is "localhost";
+// other images, e.g. "nginx" or "bitnami/redis", come from Docker Hub.
+func ImageRegistry(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return DockerHubRegistry
+	}
+	host := image[:i]
+	if !strings.ContainsAny(host, ".:") && host != "localhost" {
+		return DockerHubRegistry
+	}
+	return normalizeRegistry(host)
+}
+
+// normalizeRegistry reduces a registry given as a host or URL, such as the keys of a Docker
+// config, to its host, using DockerHubRegistry for the aliases of Docker Hub.
+func normalizeRegistry(registry string) string {
+	registry = strings.TrimPrefix(strings.TrimPrefix(registry, "https://"), "http://")
+	if i := strings.Index(registry, "/"); i >= 0 {
+		registry = registry[:i]
+	}
+	switch registry {
+	case "index.docker.io", "registry-1.docker.io":
+		return DockerHubRegistry
+	}
+	return registry
+}
+
+// ImagePullSecrets returns the names of the image pull Secrets of a ServiceAccount, or false if
+// the resource is not a ServiceAccount or they cannot be decoded.
+func (r *Resource) ImagePullSecrets() ([]string, bool) {
+	if r.Group() != "" || r.Kind != "ServiceAccount" {
+		return nil, false
+	}
+	var refs []LocalObjectReference
+	if err := r.Decode(&refs, "imagePullSecrets"); err != nil {
+		return nil, false
+	}
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Name != "" {
+			names = append(names, ref.Name)
+		}
+	}
+	return names, true
+}
+
+// DockerConfigRegistries returns the sorted registries a Docker config Secret holds credentials
+// for, read from data (base64-encoded) or stringData. It returns false if the resource is not a
+// Secret of type SecretTypeDockerConfigJSON or SecretTypeDockercfg or its config cannot be read,
+// as when the chart leaves the credentials to be filled in at install time.
+func (r *Resource) DockerConfigRegistries() ([]string, bool) {
+	if r.Group() != "" || r.Kind != "Secret" {
+		return nil, false
+	}
+	secretType, _ := r.StringField("type")
+	var key string
+	switch secretType {
+	case SecretTypeDockerConfigJSON:
+		key = dockerConfigJSONKey
+	case SecretTypeDockercfg:
+		key = dockercfgKey
+	default:
+		return nil, false
+	}
+
+	config, ok := r.StringField("stringData", key)
+	if !ok {
+		encoded, ok := r.StringField("data", key)
+		if !ok {
+			return nil, false
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, false
+		}
+		config = string(decoded)
+	}
+
+	var auths map[string]json.RawMessage
+	if key == dockerConfigJSONKey {
+		var file struct {
+			Auths map[string]json.RawMessage `json:"auths"`
+		}
+		if err := json.Unmarshal([]byte(config), &file); err != nil {
+			return nil, false
+		}
+		auths = file.Auths
+	} else if err := json.Unmarshal([]byte(config), &auths); err != nil {
+		return nil, false
+	}
+
+	registries := make([]string, 0, len(auths))
+	for registry := range auths {
+		registries = append(registries, normalizeRegistry(registry))
+	}
+	sort.Strings(registries)
+	return registries, true
+}
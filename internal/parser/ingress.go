@@ -0,0 +1,89 @@
+package parser
+
+// ServiceBackendPort is the port of a Service an Ingress routes to, by name or number.
+type ServiceBackendPort struct {
+	Name   string `yaml:"name"`
+	Number int    `yaml:"number"`
+}
+
+// IngressServiceBackend is a Service an Ingress routes to.
+type IngressServiceBackend struct {
+	Name string             `yaml:"name"`
+	Port ServiceBackendPort `yaml:"port"`
+}
+
+// TypedLocalObjectReference refers to a resource in the same namespace by API group, kind and name.
+type TypedLocalObjectReference struct {
+	APIGroup string `yaml:"apiGroup"`
+	Kind     string `yaml:"kind"`
+	Name     string `yaml:"name"`
+}
+
+// IngressBackend is the destination of Ingress traffic. ServiceName and ServicePort hold the
+// legacy extensions/v1beta1 and networking.k8s.io/v1beta1 form of the Service backend.
+type IngressBackend struct {
+	Service     *IngressServiceBackend     `yaml:"service"`
+	Resource    *TypedLocalObjectReference `yaml:"resource"`
+	ServiceName string                     `yaml:"serviceName"`
+	ServicePort string                     `yaml:"servicePort"`
+}
+
+// HTTPIngressPath maps a path to a backend.
+type HTTPIngressPath struct {
+	Path     string         `yaml:"path"`
+	PathType string         `yaml:"pathType"`
+	Backend  IngressBackend `yaml:"backend"`
+}
+
+// IngressRule maps the paths of a host to backends.
+type IngressRule struct {
+	Host string `yaml:"host"`
+	HTTP struct {
+		Paths []HTTPIngressPath `yaml:"paths"`
+	} `yaml:"http"`
+}
+
+// IngressTLS is a TLS certificate Secret and the hosts it is used for.
+type IngressTLS struct {
+	Hosts      []string `yaml:"hosts"`
+	SecretName string   `yaml:"secretName"`
+}
+
+// IngressSpec is the spec of an Ingress. Backend is the legacy name of DefaultBackend.
+type IngressSpec struct {
+	DefaultBackend *IngressBackend `yaml:"defaultBackend"`
+	Backend        *IngressBackend `yaml:"backend"`
+	Rules          []IngressRule   `yaml:"rules"`
+	TLS            []IngressTLS    `yaml:"tls"`
+}
+
+// ServiceRef returns the name and port of the Service the backend routes to, in either the
+// current or the legacy form. The port is a number, or a name if the backend uses a named port.
+func (b *IngressBackend) ServiceRef() (string, interface{}, bool) {
+	if b.Service != nil && b.Service.Name != "" {
+		if b.Service.Port.Name != "" {
+			return b.Service.Name, b.Service.Port.Name, true
+		}
+		return b.Service.Name, b.Service.Port.Number, true
+	}
+	if b.ServiceName != "" {
+		return b.ServiceName, IntOrString(b.ServicePort), true
+	}
+	return "", nil, false
+}
+
+// IngressSpec returns the spec of an Ingress, or false if the resource is not an Ingress or its
+// spec cannot be decoded.
+func (r *Resource) IngressSpec() (*IngressSpec, bool) {
+	if r.Kind != "Ingress" {
+		return nil, false
+	}
+	var spec IngressSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	if spec.DefaultBackend == nil {
+		spec.DefaultBackend = spec.Backend
+	}
+	return &spec, true
+}
@@ -1,16 +1,20 @@
 package parser
 
+import "strings"
+
 // Metadata represents the metadata of a Kubernetes resource.
 type Metadata struct {
-	Name      string            `yaml:"name"`
-	Namespace string            `yaml:"namespace"`
-	Labels    map[string]string `yaml:"labels"`
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
 }
 
 // VolumeMount represents a mounting of a Volume within a container.
 type VolumeMount struct {
 	Name      string `yaml:"name"`
 	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly"`
 }
 
 // EnvFromSource represents the source of a set of environment variables.
@@ -38,9 +42,15 @@ type EnvVar struct {
 	} `yaml:"valueFrom"`
 }
 
+// LocalObjectReference refers to a resource in the same namespace by name.
+type LocalObjectReference struct {
+	Name string `yaml:"name"`
+}
+
 // Container represents a single container that is expected to be run on a pod.
 type Container struct {
 	Name         string          `yaml:"name"`
+	Image        string          `yaml:"image"`
 	Env          []EnvVar        `yaml:"env"`
 	EnvFrom      []EnvFromSource `yaml:"envFrom"`
 	VolumeMounts []VolumeMount   `yaml:"volumeMounts"`
@@ -55,28 +65,139 @@ type Volume struct {
 	ConfigMap struct {
 		Name string `yaml:"name"`
 	} `yaml:"configMap"`
+	PersistentVolumeClaim struct {
+		ClaimName string `yaml:"claimName"`
+		ReadOnly  bool   `yaml:"readOnly"`
+	} `yaml:"persistentVolumeClaim"`
+}
+
+// PersistentVolumeClaimSpec describes the storage a PersistentVolumeClaim requests.
+type PersistentVolumeClaimSpec struct {
+	StorageClassName *string  `yaml:"storageClassName"`
+	AccessModes      []string `yaml:"accessModes"`
+	VolumeName       string   `yaml:"volumeName"`
+	Resources        struct {
+		Requests map[string]string `yaml:"requests"`
+	} `yaml:"resources"`
 }
 
 // PersistentVolumeClaim represents a PersistentVolumeClaim.
 type PersistentVolumeClaim struct {
-	APIVersion string   `yaml:"apiVersion"`
-	Kind       string   `yaml:"kind"`
-	Metadata   Metadata `yaml:"metadata"`
+	APIVersion string                    `yaml:"apiVersion"`
+	Kind       string                    `yaml:"kind"`
+	Metadata   Metadata                  `yaml:"metadata"`
+	Spec       PersistentVolumeClaimSpec `yaml:"spec"`
+}
+
+// PodAffinityTerm selects the pods a pod should, or should not, be co-located with.
+// A term without a label selector matches no pods. Pods are selected in the namespaces listed
+// and those matched by the namespace selector, or in the pod's own namespace if neither is given.
+type PodAffinityTerm struct {
+	LabelSelector     *LabelSelector `yaml:"labelSelector"`
+	Namespaces        []string       `yaml:"namespaces"`
+	NamespaceSelector *LabelSelector `yaml:"namespaceSelector"`
+	TopologyKey       string         `yaml:"topologyKey"`
+}
+
+// WeightedPodAffinityTerm is a preferred pod affinity term with its weight.
+type WeightedPodAffinityTerm struct {
+	Weight          int             `yaml:"weight"`
+	PodAffinityTerm PodAffinityTerm `yaml:"podAffinityTerm"`
+}
+
+// PodAffinity holds the required and preferred terms of a pod affinity or anti-affinity.
+type PodAffinity struct {
+	Required  []PodAffinityTerm         `yaml:"requiredDuringSchedulingIgnoredDuringExecution"`
+	Preferred []WeightedPodAffinityTerm `yaml:"preferredDuringSchedulingIgnoredDuringExecution"`
+}
+
+// Affinity holds the inter-pod scheduling constraints of a pod.
+type Affinity struct {
+	PodAffinity     PodAffinity `yaml:"podAffinity"`
+	PodAntiAffinity PodAffinity `yaml:"podAntiAffinity"`
+}
+
+// PodSpec describes the containers, volumes and scheduling constraints of a pod.
+type PodSpec struct {
+	InitContainers      []Container            `yaml:"initContainers"`
+	Containers          []Container            `yaml:"containers"`
+	EphemeralContainers []Container            `yaml:"ephemeralContainers"`
+	Volumes             []Volume               `yaml:"volumes"`
+	Affinity            Affinity               `yaml:"affinity"`
+	ImagePullSecrets    []LocalObjectReference `yaml:"imagePullSecrets"`
+	ServiceAccountName  string                 `yaml:"serviceAccountName"`
+	// DeprecatedServiceAccount is the deprecated alias of ServiceAccountName.
+	DeprecatedServiceAccount string `yaml:"serviceAccount"`
+}
+
+// PodTemplateSpec describes the pods a workload creates.
+type PodTemplateSpec struct {
+	Metadata Metadata `yaml:"metadata"`
+	Spec     PodSpec  `yaml:"spec"`
+}
+
+// JobSpec describes the pods of a Job.
+type JobSpec struct {
+	Template PodTemplateSpec `yaml:"template"`
+}
+
+// JobTemplateSpec describes the Jobs a CronJob creates.
+type JobTemplateSpec struct {
+	Spec JobSpec `yaml:"spec"`
+}
+
+// ResourceSpec holds the spec fields used to identify relationships. The pod spec fields are
+// inlined for bare Pods, whose spec is the pod spec itself.
+type ResourceSpec struct {
+	PodSpec              `yaml:",inline"`
+	Selector             LabelSelector           `yaml:"selector"`
+	Template             PodTemplateSpec         `yaml:"template"`
+	JobTemplate          JobTemplateSpec         `yaml:"jobTemplate"`
+	JobTargetRef         JobSpec                 `yaml:"jobTargetRef"`
+	VolumeClaimTemplates []PersistentVolumeClaim `yaml:"volumeClaimTemplates"`
+	ServiceName          string                  `yaml:"serviceName"`
 }
 
 // Resource represents a generic Kubernetes resource.
 type Resource struct {
-	APIVersion string   `yaml:"apiVersion"`
-	Kind       string   `yaml:"kind"`
-	Metadata   Metadata `yaml:"metadata"`
-	Spec       struct {
-		Selector Selector `yaml:"selector"`
-		Template struct {
-			Spec struct {
-				Containers []Container `yaml:"containers"`
-				Volumes    []Volume    `yaml:"volumes"`
-			} `yaml:"spec"`
-		} `yaml:"template"`
-		VolumeClaimTemplates []PersistentVolumeClaim `yaml:"volumeClaimTemplates"`
-	} `yaml:"spec"`
+	APIVersion string       `yaml:"apiVersion"`
+	Kind       string       `yaml:"kind"`
+	Metadata   Metadata     `yaml:"metadata"`
+	Spec       ResourceSpec `yaml:"spec"`
+	// Object is the full resource as decoded from the manifest, including every field not
+	// modelled above. Use Field and the typed accessors to read it.
+	Object map[string]interface{} `yaml:"-"`
+	// Source is the chart template the resource was rendered from, e.g.
+	// "app/charts/db/templates/secret.yaml". It is empty for resources not rendered by Helm.
+	Source string `yaml:"-"`
+	// Properties holds additional node properties that are not read from the manifest.
+	Properties map[string]interface{} `yaml:"-"`
+	// APIGroup is the API group of a resource without an API version, such as a placeholder or
+	// a node that does not stand for a Kubernetes object. Group reads it from APIVersion otherwise.
+	APIGroup string `yaml:"-"`
+}
+
+// ChartPath returns the path of the (sub)chart that rendered the resource, e.g. "app/charts/db",
+// or "" if the resource has no source template.
+func (r *Resource) ChartPath() string {
+	for _, dir := range []string{"/templates/", "/crds/"} {
+		if i := strings.LastIndex(r.Source, dir); i >= 0 {
+			return r.Source[:i]
+		}
+	}
+	return ""
+}
+
+// ChartChain splits a chart path into the paths of the charts it is nested in, starting with
+// the top-level chart: "app/charts/db" gives ["app", "app/charts/db"].
+func ChartChain(chartPath string) []string {
+	if chartPath == "" {
+		return nil
+	}
+	parts := strings.Split(chartPath, "/charts/")
+	chain := make([]string, len(parts))
+	for i := range parts {
+		chain[i] = strings.Join(parts[:i+1], "/charts/")
+	}
+	return chain
 }
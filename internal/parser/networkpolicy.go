@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Network policy types, which are also the directions of the traffic they control.
+const (
+	PolicyTypeIngress = "Ingress"
+	PolicyTypeEgress  = "Egress"
+)
+
+// NetworkPolicyPort is a port, or port range, that a network policy rule allows. A rule
+// without a port allows every port of the protocol, which defaults to TCP.
+type NetworkPolicyPort struct {
+	Protocol string  `yaml:"protocol"`
+	Port     *string `yaml:"port"`
+	EndPort  int     `yaml:"endPort"`
+}
+
+// String returns the port as "<protocol>/<port>", e.g. "TCP/80", "TCP/8000-9000" or "UDP/dns",
+// or just the protocol if every port is allowed.
+func (p NetworkPolicyPort) String() string {
+	protocol := p.Protocol
+	if protocol == "" {
+		protocol = "TCP"
+	}
+	switch {
+	case p.Port == nil:
+		return protocol
+	case p.EndPort != 0:
+		return fmt.Sprintf("%s/%s-%d", protocol, *p.Port, p.EndPort)
+	}
+	return protocol + "/" + *p.Port
+}
+
+// IPBlock is a CIDR range, with exceptions, that a network policy rule allows.
+type IPBlock struct {
+	CIDR   string   `yaml:"cidr"`
+	Except []string `yaml:"except"`
+}
+
+// NetworkPolicyPeer is a source or destination of traffic allowed by a network policy rule:
+// pods selected by a pod and/or namespace selector, or an IP block.
+type NetworkPolicyPeer struct {
+	PodSelector       *LabelSelector `yaml:"podSelector"`
+	NamespaceSelector *LabelSelector `yaml:"namespaceSelector"`
+	IPBlock           *IPBlock       `yaml:"ipBlock"`
+}
+
+// String returns the selectors of a pod and/or namespace selector peer, e.g.
+// "namespaceSelector: team=a; podSelector: app=web". An empty selector is written as {}.
+func (p NetworkPolicyPeer) String() string {
+	var parts []string
+	for _, s := range []struct {
+		name     string
+		selector *LabelSelector
+	}{{"namespaceSelector", p.NamespaceSelector}, {"podSelector", p.PodSelector}} {
+		if s.selector == nil {
+			continue
+		}
+		selector := s.selector.String()
+		if selector == "" {
+			selector = "{}"
+		}
+		parts = append(parts, s.name+": "+selector)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// NetworkPolicyRule is an ingress or egress rule. Peers holds the rule's from (ingress) or to
+// (egress) list; a rule without peers allows traffic from or to everywhere.
+type NetworkPolicyRule struct {
+	Ports []NetworkPolicyPort `yaml:"ports"`
+	From  []NetworkPolicyPeer `yaml:"from"`
+	To    []NetworkPolicyPeer `yaml:"to"`
+}
+
+// Peers returns the peers of the rule, whichever direction it is for.
+func (r NetworkPolicyRule) Peers() []NetworkPolicyPeer {
+	return append(r.From, r.To...)
+}
+
+// PortNames returns the ports of the rule as strings. An empty list means every port.
+func (r NetworkPolicyRule) PortNames() []string {
+	ports := make([]string, len(r.Ports))
+	for i, p := range r.Ports {
+		ports[i] = p.String()
+	}
+	return ports
+}
+
+// NetworkPolicySpec is the spec of a NetworkPolicy.
+type NetworkPolicySpec struct {
+	PodSelector LabelSelector       `yaml:"podSelector"`
+	Ingress     []NetworkPolicyRule `yaml:"ingress"`
+	Egress      []NetworkPolicyRule `yaml:"egress"`
+	PolicyTypes []string            `yaml:"policyTypes"`
+}
+
+// HasPolicyType reports whether the policy controls traffic of the given type. Without explicit
+// policyTypes, a policy always controls ingress, and egress only if it has egress rules.
+func (s *NetworkPolicySpec) HasPolicyType(policyType string) bool {
+	if len(s.PolicyTypes) == 0 {
+		return policyType == PolicyTypeIngress || (policyType == PolicyTypeEgress && len(s.Egress) > 0)
+	}
+	for _, t := range s.PolicyTypes {
+		if t == policyType {
+			return true
+		}
+	}
+	return false
+}
+
+// Rules returns the rules of the policy for the given policy type.
+func (s *NetworkPolicySpec) Rules(policyType string) []NetworkPolicyRule {
+	if policyType == PolicyTypeEgress {
+		return s.Egress
+	}
+	return s.Ingress
+}
+
+// NetworkPolicySpec returns the spec of a NetworkPolicy, or false if the resource is not one or
+// its spec cannot be decoded.
+func (r *Resource) NetworkPolicySpec() (*NetworkPolicySpec, bool) {
+	if r.Group() != "networking.k8s.io" || r.Kind != "NetworkPolicy" {
+		return nil, false
+	}
+	var spec NetworkPolicySpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Field returns the value at path in the resource's full object, e.g.
+// r.Field("spec", "template", "spec", "containers", "0", "image"). Numeric path
+// elements index into lists.
+func (r *Resource) Field(path ...string) (interface{}, bool) {
+	var current interface{} = r.Object
+	for _, p := range path {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[p]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(p)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			current = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+// StringField returns the string at path. Scalars of other types are formatted as strings,
+// since YAML manifests often leave values such as ports or versions unquoted.
+func (r *Resource) StringField(path ...string) (string, bool) {
+	v, ok := r.Field(path...)
+	if !ok {
+		return "", false
+	}
+	switch val := v.(type) {
+	case string:
+		return val, true
+	case int, int64, float64, bool:
+		return fmt.Sprintf("%v", val), true
+	}
+	return "", false
+}
+
+// IntField returns the integer at path.
+func (r *Resource) IntField(path ...string) (int64, bool) {
+	v, ok := r.Field(path...)
+	if !ok {
+		return 0, false
+	}
+	switch val := v.(type) {
+	case int:
+		return int64(val), true
+	case int64:
+		return val, true
+	case float64:
+		if val == float64(int64(val)) {
+			return int64(val), true
+		}
+	}
+	return 0, false
+}
+
+// BoolField returns the boolean at path.
+func (r *Resource) BoolField(path ...string) (bool, bool) {
+	v, ok := r.Field(path...)
+	if !ok {
+		return false, false
+	}
+	b, ok := v.(bool)
+	return b, ok
+}
+
+// MapField returns the map at path.
+func (r *Resource) MapField(path ...string) (map[string]interface{}, bool) {
+	v, ok := r.Field(path...)
+	if !ok {
+		return nil, false
+	}
+	m, ok := v.(map[string]interface{})
+	return m, ok
+}
+
+// SliceField returns the list at path.
+func (r *Resource) SliceField(path ...string) ([]interface{}, bool) {
+	v, ok := r.Field(path...)
+	if !ok {
+		return nil, false
+	}
+	s, ok := v.([]interface{})
+	return s, ok
+}
+
+// StringMapField returns the map at path with its scalar values formatted as strings,
+// as used for labels, annotations and ConfigMap data.
+func (r *Resource) StringMapField(path ...string) (map[string]string, bool) {
+	m, ok := r.MapField(path...)
+	if !ok {
+		return nil, false
+	}
+	result := make(map[string]string, len(m))
+	for k, v := range m {
+		if v == nil {
+			result[k] = ""
+			continue
+		}
+		result[k] = fmt.Sprintf("%v", v)
+	}
+	return result, true
+}
+
+// Decode decodes the value at path into out, which is typically a pointer to a struct with yaml
+// tags modelling a kind-specific part of the object. A missing path leaves out unchanged.
+func (r *Resource) Decode(out interface{}, path ...string) error {
+	v, ok := r.Field(path...)
+	if !ok {
+		return nil
+	}
+	data, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to decode %s %s: %w", r.Kind, r.Metadata.Name, err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode %s %s: %w", r.Kind, r.Metadata.Name, err)
+	}
+	return nil
+}
+
+// IntOrString returns the value of an int-or-string field as a number if it is numeric,
+// otherwise as a string such as a port name or a percentage.
+func IntOrString(value string) interface{} {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return value
+}
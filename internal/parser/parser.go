@@ -12,59 +12,105 @@ import (
 )
 
 // Parse takes a multi-document YAML manifest and returns a slice of Resource objects.
+// Documents without content, such as those holding only comments, are skipped.
 func Parse(manifest string) ([]*Resource, error) {
 	var resources []*Resource
 	decoder := yaml.NewDecoder(bytes.NewReader([]byte(manifest)))
 	resourceNum := 0
 
 	for {
-		var resource Resource
-		if err := decoder.Decode(&resource); err != nil {
+		var node yaml.Node
+		if err := decoder.Decode(&node); err != nil {
 			if err == io.EOF {
 				break
 			}
+			return nil, decodeError(manifest, resourceNum, err)
+		}
+
+		if isEmptyDocument(&node) {
+			resourceNum++
+			continue
+		}
+
+		var resource Resource
+		if err := node.Decode(&resource); err != nil {
+			return nil, decodeError(manifest, resourceNum, err)
+		}
+		if err := node.Decode(&resource.Object); err != nil {
+			return nil, decodeError(manifest, resourceNum, err)
+		}
+		resource.Source = sourceComment(&node)
+		resources = append(resources, &resource)
+		resourceNum++
+	}
+
+	return resources, nil
+}
 
-			lines := strings.Split(manifest, "\n")
-			builder := strings.Builder{}
-			builder.WriteString("\n")
-			startNum := 0
-			endNum := len(lines)
-			if strings.Contains(err.Error(), "unmarshal errors:") {
-				resourceStart := 0
-				resourceCount := 0
-				for i, line := range lines {
-					if line == "---" {
-						if resourceCount < resourceNum {
-							resourceCount++
-							continue
-						}
-						resourceStart = i
-						break
-					}
+// isEmptyDocument reports whether a decoded document has no content.
+func isEmptyDocument(node *yaml.Node) bool {
+	if len(node.Content) == 0 {
+		return true
+	}
+	content := node.Content[0]
+	return content.Kind == yaml.ScalarNode && content.Tag == "!!null"
+}
+
+// sourceComment returns the template path from the "# Source: <path>" comment that
+// 'helm template' puts at the top of each document, or "" if there is none.
+func sourceComment(node *yaml.Node) string {
+	for n := node; n != nil; {
+		for _, line := range strings.Split(n.HeadComment, "\n") {
+			if path, ok := strings.CutPrefix(strings.TrimSpace(line), "# Source:"); ok {
+				return strings.TrimSpace(path)
+			}
+		}
+		if len(n.Content) == 0 {
+			break
+		}
+		n = n.Content[0]
+	}
+	return ""
+}
+
+// decodeError builds a decoding error for the given resource, including the lines of the
+// manifest around the reported line number when there is one.
+func decodeError(manifest string, resourceNum int, err error) error {
+	lines := strings.Split(manifest, "\n")
+	builder := strings.Builder{}
+	builder.WriteString("\n")
+	startNum := 0
+	endNum := len(lines)
+	if strings.Contains(err.Error(), "unmarshal errors:") {
+		resourceStart := 0
+		resourceCount := 0
+		for i, line := range lines {
+			if line == "---" {
+				if resourceCount < resourceNum {
+					resourceCount++
+					continue
 				}
+				resourceStart = i
+				break
+			}
+		}
 
-				re := regexp.MustCompile(`line (\d+):`)
-				matches := re.FindStringSubmatch(err.Error())
-				if len(matches) > 1 {
-					if num, err := strconv.Atoi(matches[1]); err == nil {
-						if resourceStart+num-3 > 0 {
-							startNum = resourceStart + num - 3
-						}
-						if resourceStart+num+3 < len(lines) {
-							endNum = resourceStart + num + 3
-						}
-					}
+		re := regexp.MustCompile(`line (\d+):`)
+		matches := re.FindStringSubmatch(err.Error())
+		if len(matches) > 1 {
+			if num, err := strconv.Atoi(matches[1]); err == nil {
+				if resourceStart+num-3 > 0 {
+					startNum = resourceStart + num - 3
 				}
-				for i := startNum; i < endNum; i++ {
-					builder.WriteString(fmt.Sprintf("%d. %s\n", i+1-resourceStart, lines[i]))
+				if resourceStart+num+3 < len(lines) {
+					endNum = resourceStart + num + 3
 				}
 			}
-
-			return nil, fmt.Errorf("error decoding YAML resource %d: %w\n\nManifest:\n %s", resourceNum, err, builder.String())
 		}
-		resources = append(resources, &resource)
-		resourceNum++
+		for i := startNum; i < endNum; i++ {
+			builder.WriteString(fmt.Sprintf("%d. %s\n", i+1-resourceStart, lines[i]))
+		}
 	}
 
-	return resources, nil
+	return fmt.Errorf("error decoding YAML resource %d: %w\n\nManifest:\n %s", resourceNum, err, builder.String())
 }
@@ -42,3 +42,499 @@ spec:
 		t.Errorf("unexpected resource[1]: %+v", resources[1])
 	}
 }
+
+func TestParseSource(t *testing.T) {
+	manifest := `---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: my-service
+---
+# Source: app/templates/empty.yaml
+---
+# Source: app/charts/db/templates/secret.yaml
+# a comment from the template
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-credentials
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(resources) != 3 {
+		t.Fatalf("expected 3 resources, but got %d", len(resources))
+	}
+
+	expected := []struct {
+		source    string
+		chartPath string
+	}{
+		{"app/templates/service.yaml", "app"},
+		{"app/charts/db/templates/secret.yaml", "app/charts/db"},
+		{"", ""},
+	}
+	for i, e := range expected {
+		if resources[i].Source != e.source {
+			t.Errorf("resource[%d]: expected source %q, but got %q", i, e.source, resources[i].Source)
+		}
+		if resources[i].ChartPath() != e.chartPath {
+			t.Errorf("resource[%d]: expected chart path %q, but got %q", i, e.chartPath, resources[i].ChartPath())
+		}
+	}
+
+	chain := ChartChain("app/charts/db/charts/common")
+	expectedChain := []string{"app", "app/charts/db", "app/charts/db/charts/common"}
+	if len(chain) != len(expectedChain) {
+		t.Fatalf("expected chain %v, but got %v", expectedChain, chain)
+	}
+	for i := range chain {
+		if chain[i] != expectedChain[i] {
+			t.Errorf("expected chain %v, but got %v", expectedChain, chain)
+		}
+	}
+}
+
+func TestResourceHook(t *testing.T) {
+	manifest := `
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: db-migrate
+  annotations:
+    helm.sh/hook: pre-install, pre-upgrade
+    helm.sh/hook-weight: "-5"
+    helm.sh/hook-delete-policy: before-hook-creation,hook-succeeded
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hook := resources[0].Hook()
+	if hook == nil {
+		t.Fatal("expected db-migrate to be a hook")
+	}
+	if len(hook.Phases) != 2 || !hook.HasPhase("pre-install") || !hook.HasPhase("pre-upgrade") {
+		t.Errorf("unexpected hook phases: %v", hook.Phases)
+	}
+	if hook.Weight != -5 {
+		t.Errorf("expected weight -5, but got %d", hook.Weight)
+	}
+	if len(hook.DeletePolicies) != 2 || hook.DeletePolicies[1] != "hook-succeeded" {
+		t.Errorf("unexpected delete policies: %v", hook.DeletePolicies)
+	}
+
+	if resources[1].Hook() != nil {
+		t.Errorf("expected my-configmap not to be a hook")
+	}
+}
+
+func TestResourceObject(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-deployment
+  annotations:
+    prometheus.io/port: 9090
+spec:
+  replicas: 3
+  paused: false
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:1.25
+          ports:
+            - containerPort: 8080
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := resources[0]
+
+	if replicas, ok := r.IntField("spec", "replicas"); !ok || replicas != 3 {
+		t.Errorf("expected 3 replicas, but got %d (%v)", replicas, ok)
+	}
+	if paused, ok := r.BoolField("spec", "paused"); !ok || paused {
+		t.Errorf("expected paused to be false, but got %v (%v)", paused, ok)
+	}
+	if image, ok := r.StringField("spec", "template", "spec", "containers", "0", "image"); !ok || image != "nginx:1.25" {
+		t.Errorf("expected image nginx:1.25, but got %q (%v)", image, ok)
+	}
+	if port, ok := r.StringField("spec", "template", "spec", "containers", "0", "ports", "0", "containerPort"); !ok || port != "8080" {
+		t.Errorf("expected port 8080, but got %q (%v)", port, ok)
+	}
+	if annotations, ok := r.StringMapField("metadata", "annotations"); !ok || annotations["prometheus.io/port"] != "9090" {
+		t.Errorf("unexpected annotations: %v (%v)", annotations, ok)
+	}
+	if containers, ok := r.SliceField("spec", "template", "spec", "containers"); !ok || len(containers) != 1 {
+		t.Errorf("expected 1 container, but got %v (%v)", containers, ok)
+	}
+
+	for _, path := range [][]string{
+		{"spec", "strategy"},
+		{"spec", "template", "spec", "containers", "1"},
+		{"spec", "replicas", "value"},
+	} {
+		if v, ok := r.Field(path...); ok {
+			t.Errorf("expected no value at %v, but got %v", path, v)
+		}
+	}
+}
+
+func TestResourcePodTemplate(t *testing.T) {
+	manifest := `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: my-cronjob
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: backup
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: my-pod
+  labels:
+    app: my-app
+spec:
+  containers:
+    - name: app
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: my-daemonset
+spec:
+  template:
+    spec:
+      containers:
+        - name: agent
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: my-service
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for i, container := range []string{"backup", "app", "agent"} {
+		template, ok := resources[i].PodTemplate()
+		if !ok {
+			t.Fatalf("expected %s to have a pod template", resources[i].Kind)
+		}
+		if len(template.Spec.Containers) != 1 || template.Spec.Containers[0].Name != container {
+			t.Errorf("expected %s to run container %s, but got %+v", resources[i].Kind, container, template.Spec.Containers)
+		}
+	}
+
+	if template, _ := resources[1].PodTemplate(); template.Metadata.Labels["app"] != "my-app" {
+		t.Errorf("expected the pod template of a Pod to carry its labels, but got %v", template.Metadata.Labels)
+	}
+
+	if resources[3].IsWorkload() {
+		t.Error("expected a Service not to be a workload")
+	}
+}
+
+func TestPodSpecAllContainers(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: my-pod
+spec:
+  initContainers:
+    - name: init
+      volumeMounts:
+        - name: config
+          mountPath: /config
+  containers:
+    - name: app
+      volumeMounts:
+        - name: config
+          mountPath: /etc/app
+  ephemeralContainers:
+    - name: debugger
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	template, _ := resources[0].PodTemplate()
+	containers := template.Spec.AllContainers()
+	expected := []struct{ name, containerType string }{
+		{"init", ContainerTypeInit},
+		{"app", ContainerTypeMain},
+		{"debugger", ContainerTypeEphemeral},
+	}
+	if len(containers) != len(expected) {
+		t.Fatalf("expected %d containers, but got %d", len(expected), len(containers))
+	}
+	for i, e := range expected {
+		if containers[i].Name != e.name || containers[i].Type != e.containerType {
+			t.Errorf("expected container %s of type %s, but got %s of type %s", e.name, e.containerType, containers[i].Name, containers[i].Type)
+		}
+	}
+
+	if mounting := template.Spec.MountingContainers("config"); len(mounting) != 2 {
+		t.Errorf("expected 2 containers to mount the config volume, but got %d", len(mounting))
+	}
+}
+
+func TestLabelSelector(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: my-service
+spec:
+  selector:
+    app: my-app
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-deployment
+spec:
+  selector:
+    matchLabels:
+      app: my-app
+    matchExpressions:
+      - key: tier
+        operator: In
+        values: [backend, cache]
+      - key: track
+        operator: NotIn
+        values: [canary]
+      - key: release
+        operator: Exists
+      - key: legacy
+        operator: DoesNotExist
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	service := resources[0].Spec.Selector
+	if service.MatchLabels["app"] != "my-app" || len(service.MatchExpressions) != 0 {
+		t.Errorf("expected a plain selector to be decoded as matchLabels, but got %+v", service)
+	}
+
+	selector := resources[1].Spec.Selector
+	if expected := "app=my-app,tier in (backend,cache),track notin (canary),release,!legacy"; selector.String() != expected {
+		t.Errorf("expected selector %q, but got %q", expected, selector.String())
+	}
+
+	tests := []struct {
+		labels  map[string]string
+		matches bool
+	}{
+		{map[string]string{"app": "my-app", "tier": "backend", "release": "r1"}, true},
+		{map[string]string{"app": "my-app", "tier": "cache", "track": "stable", "release": "r1"}, true},
+		{map[string]string{"app": "my-app", "tier": "frontend", "release": "r1"}, false},
+		{map[string]string{"app": "my-app", "tier": "backend", "track": "canary", "release": "r1"}, false},
+		{map[string]string{"app": "my-app", "tier": "backend"}, false},
+		{map[string]string{"app": "my-app", "tier": "backend", "release": "r1", "legacy": "true"}, false},
+		{map[string]string{"app": "other", "tier": "backend", "release": "r1"}, false},
+	}
+	for _, test := range tests {
+		if got := selector.Matches(test.labels); got != test.matches {
+			t.Errorf("expected Matches(%v) to be %t, but got %t", test.labels, test.matches, got)
+		}
+	}
+
+	if !(LabelSelector{}).Matches(map[string]string{"app": "my-app"}) {
+		t.Error("expected an empty selector to match everything")
+	}
+	unknown := LabelSelector{MatchExpressions: []LabelSelectorRequirement{{Key: "app", Operator: "Gt"}}}
+	if unknown.Matches(map[string]string{"app": "my-app"}) {
+		t.Error("expected a requirement with an unknown operator to match nothing")
+	}
+}
+
+func TestResolveNamespaces(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: my-deployment
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: my-configmap
+  namespace: other
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: my-role
+  namespace: ignored
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ResolveNamespaces(resources, "my-namespace")
+
+	expected := []Identity{
+		{Group: "apps", Kind: "Deployment", Namespace: "my-namespace", Name: "my-deployment"},
+		{Kind: "ConfigMap", Namespace: "other", Name: "my-configmap"},
+		{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: "my-role"},
+	}
+	for i, id := range expected {
+		if got := resources[i].Identity(); got != id {
+			t.Errorf("expected identity %s, but got %s", id, got)
+		}
+	}
+
+	if ref := resources[0].Reference("", "Secret", "my-secret"); ref.Namespace != "my-namespace" {
+		t.Errorf("expected a Secret reference to resolve in the referring namespace, but got %s", ref)
+	}
+	if ref := resources[0].Reference("storage.k8s.io", "StorageClass", "fast"); ref.String() != "StorageClass.storage.k8s.io/fast" {
+		t.Errorf("expected a cluster-scoped reference without namespace, but got %s", ref)
+	}
+
+	ResolveNamespaces(resources[:1], "")
+	if resources[0].Metadata.Namespace != "my-namespace" {
+		t.Errorf("expected an explicit namespace to be kept, but got %s", resources[0].Metadata.Namespace)
+	}
+	defaulted := &Resource{Kind: "Service"}
+	ResolveNamespaces([]*Resource{defaulted}, "")
+	if defaulted.Metadata.Namespace != DefaultNamespace {
+		t.Errorf("expected namespace %s, but got %s", DefaultNamespace, defaulted.Metadata.Namespace)
+	}
+}
+
+func TestResourceIngressSpec(t *testing.T) {
+	manifest := `
+apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: my-ingress
+spec:
+  backend:
+    serviceName: my-service
+    servicePort: http
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spec, ok := resources[0].IngressSpec()
+	if !ok || spec.DefaultBackend == nil {
+		t.Fatalf("expected the legacy backend to be decoded as the default backend, but got %+v", spec)
+	}
+	if name, port, ok := spec.DefaultBackend.ServiceRef(); !ok || name != "my-service" || port != "http" {
+		t.Errorf("expected backend my-service:http, but got %s:%v", name, port)
+	}
+}
+
+func TestPodDisruptionBudgetAllowedDisruptions(t *testing.T) {
+	value := func(s string) *string { return &s }
+	tests := []struct {
+		spec     PodDisruptionBudgetSpec
+		replicas int
+		allowed  int
+	}{
+		{PodDisruptionBudgetSpec{MinAvailable: value("2")}, 3, 1},
+		{PodDisruptionBudgetSpec{MinAvailable: value("2")}, 2, 0},
+		{PodDisruptionBudgetSpec{MinAvailable: value("50%")}, 3, 1},
+		{PodDisruptionBudgetSpec{MinAvailable: value("100%")}, 3, 0},
+		{PodDisruptionBudgetSpec{MaxUnavailable: value("0")}, 3, 0},
+		{PodDisruptionBudgetSpec{MaxUnavailable: value("10%")}, 3, 1},
+		{PodDisruptionBudgetSpec{}, 3, 3},
+	}
+	for _, test := range tests {
+		if got := test.spec.AllowedDisruptions(test.replicas); got != test.allowed {
+			t.Errorf("expected %+v to allow %d disruptions of %d replicas, but got %d", test.spec, test.allowed, test.replicas, got)
+		}
+	}
+}
+
+func TestImageRegistry(t *testing.T) {
+	tests := map[string]string{
+		"nginx:1.27":                         "docker.io",
+		"bitnami/redis":                      "docker.io",
+		"index.docker.io/library/nginx":      "docker.io",
+		"ghcr.io/org/app@sha256:0123":        "ghcr.io",
+		"localhost/app":                      "localhost",
+		"registry.internal:5000/team/app:v1": "registry.internal:5000",
+	}
+	for image, expected := range tests {
+		if got := ImageRegistry(image); got != expected {
+			t.Errorf("expected registry %q for %q, but got %q", expected, image, got)
+		}
+	}
+}
+
+func TestDockerConfigRegistries(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: encoded
+type: kubernetes.io/dockerconfigjson
+data:
+  .dockerconfigjson: eyJhdXRocyI6eyJodHRwczovL2luZGV4LmRvY2tlci5pby92MS8iOnt9LCJnaGNyLmlvIjp7fX19
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: plain
+type: kubernetes.io/dockercfg
+stringData:
+  .dockercfg: '{"quay.io": {}}'
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: templated
+type: kubernetes.io/dockerconfigjson
+`
+	resources, err := Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	registries, ok := resources[0].DockerConfigRegistries()
+	if !ok || len(registries) != 2 || registries[0] != "docker.io" || registries[1] != "ghcr.io" {
+		t.Errorf("expected registries [docker.io ghcr.io], but got %v", registries)
+	}
+	registries, ok = resources[1].DockerConfigRegistries()
+	if !ok || len(registries) != 1 || registries[0] != "quay.io" {
+		t.Errorf("expected registries [quay.io], but got %v", registries)
+	}
+	if _, ok := resources[2].DockerConfigRegistries(); ok {
+		t.Errorf("expected the registries of a Secret without config to be unknown")
+	}
+}
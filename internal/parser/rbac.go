@@ -0,0 +1,84 @@
+package parser
+
+// RBACGroup is the API group of Roles, ClusterRoles and their bindings.
+const RBACGroup = "rbac.authorization.k8s.io"
+
+// PolicyRule is a rule of a Role or ClusterRole granting verbs on resources.
+type PolicyRule struct {
+	APIGroups       []string `yaml:"apiGroups"`
+	Resources       []string `yaml:"resources"`
+	ResourceNames   []string `yaml:"resourceNames"`
+	Verbs           []string `yaml:"verbs"`
+	NonResourceURLs []string `yaml:"nonResourceURLs"`
+}
+
+// AggregationRule selects the ClusterRoles whose rules are aggregated into a ClusterRole.
+type AggregationRule struct {
+	ClusterRoleSelectors []LabelSelector `yaml:"clusterRoleSelectors"`
+}
+
+// RoleRef refers to the Role or ClusterRole a binding grants.
+type RoleRef struct {
+	APIGroup string `yaml:"apiGroup"`
+	Kind     string `yaml:"kind"`
+	Name     string `yaml:"name"`
+}
+
+// Subject is a ServiceAccount, User or Group a binding grants a role to.
+type Subject struct {
+	Kind      string `yaml:"kind"`
+	APIGroup  string `yaml:"apiGroup"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+}
+
+// Identity returns the identity of the subject. ServiceAccounts without a namespace are in the
+// namespace of the binding; Users and Groups are cluster-wide.
+func (s Subject) Identity(binding *Resource) Identity {
+	if s.Kind == "ServiceAccount" {
+		id := binding.Reference("", "ServiceAccount", s.Name)
+		if s.Namespace != "" {
+			id.Namespace = s.Namespace
+		}
+		return id
+	}
+	return Identity{Group: RBACGroup, Kind: s.Kind, Name: s.Name}
+}
+
+// Role holds the rules of a Role or ClusterRole.
+type Role struct {
+	Rules           []PolicyRule     `yaml:"rules"`
+	AggregationRule *AggregationRule `yaml:"aggregationRule"`
+}
+
+// RoleBinding holds the role and subjects of a RoleBinding or ClusterRoleBinding.
+type RoleBinding struct {
+	RoleRef  RoleRef   `yaml:"roleRef"`
+	Subjects []Subject `yaml:"subjects"`
+}
+
+// Role returns the rules of a Role or ClusterRole, or false if the resource is neither or
+// cannot be decoded.
+func (r *Resource) Role() (*Role, bool) {
+	if r.Group() != RBACGroup || (r.Kind != "Role" && r.Kind != "ClusterRole") {
+		return nil, false
+	}
+	var role Role
+	if err := r.Decode(&role); err != nil {
+		return nil, false
+	}
+	return &role, true
+}
+
+// RoleBinding returns the role and subjects of a RoleBinding or ClusterRoleBinding, or false if
+// the resource is neither or cannot be decoded.
+func (r *Resource) RoleBinding() (*RoleBinding, bool) {
+	if r.Group() != RBACGroup || (r.Kind != "RoleBinding" && r.Kind != "ClusterRoleBinding") {
+		return nil, false
+	}
+	var binding RoleBinding
+	if err := r.Decode(&binding); err != nil {
+		return nil, false
+	}
+	return &binding, true
+}
@@ -0,0 +1,24 @@
+package parser
+
+// DefaultStorageClassAnnotation marks the StorageClass used by claims that do not name one.
+const DefaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// PersistentVolumeClaimSpec returns the spec of a PersistentVolumeClaim, or false if the
+// resource is not one or its spec cannot be decoded.
+func (r *Resource) PersistentVolumeClaimSpec() (*PersistentVolumeClaimSpec, bool) {
+	if r.Group() != "" || r.Kind != "PersistentVolumeClaim" {
+		return nil, false
+	}
+	var spec PersistentVolumeClaimSpec
+	if err := r.Decode(&spec, "spec"); err != nil {
+		return nil, false
+	}
+	return &spec, true
+}
+
+// IsDefaultStorageClass reports whether the resource is a StorageClass annotated as the
+// cluster's default.
+func (r *Resource) IsDefaultStorageClass() bool {
+	return r.Group() == "storage.k8s.io" && r.Kind == "StorageClass" &&
+		r.Metadata.Annotations[DefaultStorageClassAnnotation] == "true"
+}
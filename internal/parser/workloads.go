@@ -0,0 +1,99 @@
+package parser
+
+// Container types, distinguishing the lists a container is declared in.
+const (
+	ContainerTypeInit      = "init"
+	ContainerTypeMain      = "main"
+	ContainerTypeEphemeral = "ephemeral"
+)
+
+// PodContainer is a container of a pod together with its container type.
+type PodContainer struct {
+	Container
+	Type string
+}
+
+// AllContainers returns every container of the pod: init containers first, then the main
+// containers, then ephemeral containers.
+func (s *PodSpec) AllContainers() []PodContainer {
+	var containers []PodContainer
+	for _, group := range []struct {
+		containers    []Container
+		containerType string
+	}{
+		{s.InitContainers, ContainerTypeInit},
+		{s.Containers, ContainerTypeMain},
+		{s.EphemeralContainers, ContainerTypeEphemeral},
+	} {
+		for _, c := range group.containers {
+			containers = append(containers, PodContainer{Container: c, Type: group.containerType})
+		}
+	}
+	return containers
+}
+
+// ServiceAccount returns the name of the ServiceAccount the pod runs as, which defaults to "default".
+func (s *PodSpec) ServiceAccount() string {
+	switch {
+	case s.ServiceAccountName != "":
+		return s.ServiceAccountName
+	case s.DeprecatedServiceAccount != "":
+		return s.DeprecatedServiceAccount
+	}
+	return "default"
+}
+
+// MountingContainers returns the containers that mount the named volume.
+func (s *PodSpec) MountingContainers(volume string) []PodContainer {
+	var containers []PodContainer
+	for _, c := range s.AllContainers() {
+		for _, m := range c.VolumeMounts {
+			if m.Name == volume {
+				containers = append(containers, c)
+				break
+			}
+		}
+	}
+	return containers
+}
+
+// MountPaths returns the paths at which the container mounts the named volume, and whether
+// every one of those mounts is read-only.
+func (c *Container) MountPaths(volume string) ([]string, bool) {
+	paths := []string{}
+	readOnly := true
+	for _, m := range c.VolumeMounts {
+		if m.Name == volume {
+			paths = append(paths, m.MountPath)
+			readOnly = readOnly && m.ReadOnly
+		}
+	}
+	return paths, readOnly && len(paths) > 0
+}
+
+// PodTemplate returns the template of the pods the resource runs, for every workload kind:
+// Deployments, StatefulSets, DaemonSets, ReplicaSets, ReplicationControllers and Jobs use
+// spec.template, CronJobs spec.jobTemplate.spec.template, KEDA ScaledJobs
+// spec.jobTargetRef.template, and a bare Pod is its own template.
+// It returns false for resources that do not run pods.
+func (r *Resource) PodTemplate() (*PodTemplateSpec, bool) {
+	switch r.Kind {
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "Job":
+		return &r.Spec.Template, true
+	case "CronJob":
+		return &r.Spec.JobTemplate.Spec.Template, true
+	case "Pod":
+		return &PodTemplateSpec{Metadata: r.Metadata, Spec: r.Spec.PodSpec}, true
+	case "ScaledJob":
+		if r.Group() == KEDAGroup {
+			return &r.Spec.JobTargetRef.Template, true
+		}
+	}
+	return nil, false
+}
+
+// IsWorkload reports whether the resource runs pods.
+func (r *Resource) IsWorkload() bool {
+	_, ok := r.PodTemplate()
+	return ok
+}
@@ -0,0 +1,68 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// identifyAffinity links a workload to the workloads whose pods its pod affinity and
+// anti-affinity terms select, matching the terms against their pod template labels.
+func identifyAffinity(workload *parser.Resource, spec *parser.PodSpec, resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	for _, a := range []struct {
+		affinity parser.PodAffinity
+		relType  string
+	}{
+		{spec.Affinity.PodAffinity, "AFFINITY_TO"},
+		{spec.Affinity.PodAntiAffinity, "ANTI_AFFINITY_TO"},
+	} {
+		for _, term := range a.affinity.Required {
+			relationships = append(relationships, affinityRelationships(workload, term, a.relType, nil, resources)...)
+		}
+		for _, term := range a.affinity.Preferred {
+			relationships = append(relationships, affinityRelationships(workload, term.PodAffinityTerm, a.relType, &term.Weight, resources)...)
+		}
+	}
+	return relationships
+}
+
+// affinityRelationships returns a relationship of type relType from workload to every workload
+// whose pods are selected by term. A required term has no weight; a preferred one has its weight.
+func affinityRelationships(workload *parser.Resource, term parser.PodAffinityTerm, relType string, weight *int, resources []*parser.Resource) []*Relationship {
+	if term.LabelSelector == nil {
+		return nil
+	}
+
+	var relationships []*Relationship
+	for _, r := range resources {
+		template, ok := r.PodTemplate()
+		if !ok || !affinityNamespace(workload, term, r.Metadata.Namespace, resources) || !term.LabelSelector.Matches(template.Metadata.Labels) {
+			continue
+		}
+		properties := map[string]interface{}{
+			"required":     weight == nil,
+			"selector":     term.LabelSelector.String(),
+			"topology_key": term.TopologyKey,
+		}
+		if weight != nil {
+			properties["weight"] = *weight
+		}
+		relationships = append(relationships, &Relationship{
+			Source:     workload,
+			Target:     r,
+			Type:       relType,
+			Properties: properties,
+		})
+	}
+	return relationships
+}
+
+// affinityNamespace reports whether term selects pods in namespace.
+func affinityNamespace(workload *parser.Resource, term parser.PodAffinityTerm, namespace string, resources []*parser.Resource) bool {
+	if len(term.Namespaces) == 0 && term.NamespaceSelector == nil {
+		return namespace == workload.Metadata.Namespace
+	}
+	for _, n := range term.Namespaces {
+		if n == namespace {
+			return true
+		}
+	}
+	return term.NamespaceSelector != nil && namespaceSelected(*term.NamespaceSelector, namespace, resources)
+}
@@ -0,0 +1,136 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyAffinity(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      affinity:
+        podAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+            - weight: 50
+              podAffinityTerm:
+                topologyKey: topology.kubernetes.io/zone
+                labelSelector:
+                  matchExpressions:
+                    - key: app
+                      operator: In
+                      values: [cache, db]
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            - topologyKey: kubernetes.io/hostname
+              labelSelector:
+                matchLabels:
+                  app: web
+            - topologyKey: kubernetes.io/hostname
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: cache
+spec:
+  template:
+    metadata:
+      labels:
+        app: cache
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    metadata:
+      labels:
+        app: worker
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := identifyAffinity(resources[0], &resources[0].Spec.Template.Spec, resources)
+	if len(relationships) != 2 {
+		t.Fatalf("expected 2 relationships, but got %d", len(relationships))
+	}
+
+	affinity := relationships[0]
+	if affinity.Type != "AFFINITY_TO" || affinity.Target.Metadata.Name != "cache" {
+		t.Errorf("expected web to have affinity to cache, but got %s to %s", affinity.Type, affinity.Target.Metadata.Name)
+	}
+	if affinity.Properties["required"] != false || affinity.Properties["weight"] != 50 || affinity.Properties["selector"] != "app in (cache,db)" {
+		t.Errorf("unexpected affinity properties %v", affinity.Properties)
+	}
+
+	antiAffinity := relationships[1]
+	if antiAffinity.Type != "ANTI_AFFINITY_TO" || antiAffinity.Target.Metadata.Name != "web" {
+		t.Errorf("expected web to have anti-affinity to itself, but got %s to %s", antiAffinity.Type, antiAffinity.Target.Metadata.Name)
+	}
+	if antiAffinity.Properties["required"] != true || antiAffinity.Properties["topology_key"] != "kubernetes.io/hostname" {
+		t.Errorf("unexpected anti-affinity properties %v", antiAffinity.Properties)
+	}
+}
+
+func TestIdentifyAffinityProperties(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      affinity:
+        podAntiAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            - labelSelector:
+                matchLabels:
+                  tier: cache
+              topologyKey: kubernetes.io/hostname
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: redis
+spec:
+  template:
+    metadata:
+      labels:
+        tier: cache
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: memcached
+spec:
+  template:
+    metadata:
+      labels:
+        tier: cache
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := identifyAffinity(resources[0], &resources[0].Spec.Template.Spec, resources)
+	if len(relationships) != 2 {
+		t.Fatalf("expected 2 relationships, but got %d", len(relationships))
+	}
+	relationships[0].Properties["selector"] = "changed"
+	if relationships[1].Properties["selector"] != "tier=cache" {
+		t.Errorf("expected every relationship to have its own properties, but got %v", relationships[1].Properties)
+	}
+}
@@ -0,0 +1,35 @@
+package relations
+
+import (
+	"fmt"
+
+	"helmgraph/internal/parser"
+)
+
+// Severity classifies a Finding.
+type Severity string
+
+const (
+	SeverityInfo    Severity = "info"
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is an issue found while analysing the resources and their relationships, reported
+// against the resource it concerns.
+type Finding struct {
+	Resource *parser.Resource
+	Severity Severity
+	Message  string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s %s", f.Severity, f.Resource.Identity(), f.Message)
+}
+
+// Analyze reports issues in the resources and the relationships identified between them.
+func Analyze(resources []*parser.Resource, relationships []*Relationship) []Finding {
+	findings := analyzeDisruptionBudgets(resources, relationships)
+	findings = append(findings, analyzeRBAC(relationships)...)
+	findings = append(findings, analyzePullSecrets(relationships)...)
+	return findings
+}
@@ -0,0 +1,119 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// identifyAutoscalers links HorizontalPodAutoscalers and KEDA ScaledObjects to the workloads
+// they scale with SCALES {min, max, metrics}. A KEDA ScaledJob creates Jobs from its
+// jobTargetRef rather than scaling a workload, so it SCALES a JobTemplate node named after it
+// that stands for those Jobs. KEDA triggers are linked to their trigger authentications with
+// AUTHENTICATES_WITH, and TriggerAuthentications to the Secrets they read with USES_SECRET.
+func identifyAutoscalers(resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	missing := placeholders{}
+
+	for _, r := range resources {
+		if spec, ok := r.HorizontalPodAutoscalerSpec(); ok {
+			minReplicas := 1
+			if spec.MinReplicas != nil {
+				minReplicas = *spec.MinReplicas
+			}
+			relationships = append(relationships, scales(r, spec.ScaleTargetRef.Identity(r, "", ""), map[string]interface{}{
+				"min":     minReplicas,
+				"max":     spec.MaxReplicas,
+				"metrics": spec.MetricNames(),
+			}, resources, missing)...)
+		}
+
+		if spec, ok := r.ScaledObjectSpec(); ok {
+			minReplicas, maxReplicas := 0, 100
+			if spec.MinReplicaCount != nil {
+				minReplicas = *spec.MinReplicaCount
+			}
+			if spec.MaxReplicaCount != nil {
+				maxReplicas = *spec.MaxReplicaCount
+			}
+			triggers := make([]string, len(spec.Triggers))
+			for i, t := range spec.Triggers {
+				triggers[i] = t.String()
+			}
+			properties := map[string]interface{}{
+				"min":     minReplicas,
+				"max":     maxReplicas,
+				"metrics": triggers,
+			}
+			if r.Kind == "ScaledJob" {
+				relationships = append(relationships, &Relationship{Source: r, Target: jobTemplateNode(r), Type: "SCALES", Properties: properties})
+			} else {
+				relationships = append(relationships, scales(r, spec.ScaleTargetRef.Identity(r, "apps/v1", "Deployment"), properties, resources, missing)...)
+			}
+
+			for _, t := range spec.Triggers {
+				if t.AuthenticationRef == nil || t.AuthenticationRef.Name == "" {
+					continue
+				}
+				kind := t.AuthenticationRef.Kind
+				if kind == "" {
+					kind = "TriggerAuthentication"
+				}
+				for _, a := range findResources(resources, r.Reference(parser.KEDAGroup, kind, t.AuthenticationRef.Name)) {
+					relationships = append(relationships, &Relationship{
+						Source: r,
+						Target: a,
+						Type:   "AUTHENTICATES_WITH",
+						Properties: map[string]interface{}{
+							"trigger": t.String(),
+						},
+					})
+				}
+			}
+		}
+
+		// The Secrets of a ClusterTriggerAuthentication live in the KEDA namespace, which the
+		// manifest does not tell, so only namespaced TriggerAuthentications are resolved.
+		if spec, ok := r.TriggerAuthenticationSpec(); ok && r.Kind == "TriggerAuthentication" {
+			for _, ref := range spec.SecretTargetRef {
+				for _, s := range findResources(resources, r.Reference("", "Secret", ref.Name)) {
+					relationships = append(relationships, &Relationship{
+						Source: r,
+						Target: s,
+						Type:   "USES_SECRET",
+						Properties: map[string]interface{}{
+							"parameter": ref.Parameter,
+							"key":       ref.Key,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	return relationships
+}
+
+// jobTemplateNode returns a node standing for the Jobs a ScaledJob creates from its jobTargetRef.
+func jobTemplateNode(scaledJob *parser.Resource) *parser.Resource {
+	return &parser.Resource{
+		Kind:     "JobTemplate",
+		APIGroup: parser.KEDAGroup,
+		Metadata: parser.Metadata{Name: scaledJob.Metadata.Name, Namespace: scaledJob.Metadata.Namespace},
+	}
+}
+
+// scales returns the SCALES relationships from an autoscaler to the workload identified by target.
+func scales(autoscaler *parser.Resource, target parser.Identity, properties map[string]interface{}, resources []*parser.Resource, missing placeholders) []*Relationship {
+	targets, resolved := missing.resolve(resources, target)
+	if !resolved {
+		properties = withProperties(properties, map[string]interface{}{"unresolved": true})
+	}
+
+	var relationships []*Relationship
+	for _, t := range targets {
+		relationships = append(relationships, &Relationship{
+			Source:     autoscaler,
+			Target:     t,
+			Type:       "SCALES",
+			Properties: properties,
+		})
+	}
+	return relationships
+}
@@ -0,0 +1,149 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"reflect"
+	"testing"
+)
+
+func TestIdentifyAutoscalers(t *testing.T) {
+	manifest := `
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 2
+  maxReplicas: 10
+  metrics:
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: 80
+    - type: External
+      external:
+        metric:
+          name: queue_length
+        target:
+          type: AverageValue
+          averageValue: "30"
+---
+apiVersion: autoscaling/v1
+kind: HorizontalPodAutoscaler
+metadata:
+  name: legacy
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: StatefulSet
+    name: missing
+  maxReplicas: 3
+  targetCPUUtilizationPercentage: 50
+---
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  name: worker
+spec:
+  scaleTargetRef:
+    name: worker
+  maxReplicaCount: 20
+  triggers:
+    - type: rabbitmq
+      name: jobs
+      authenticationRef:
+        name: rabbitmq-auth
+---
+apiVersion: keda.sh/v1alpha1
+kind: ScaledJob
+metadata:
+  name: batch
+spec:
+  jobTargetRef:
+    template:
+      spec:
+        containers:
+          - name: batch
+            envFrom:
+              - secretRef:
+                  name: rabbitmq-credentials
+  triggers:
+    - type: cron
+---
+apiVersion: keda.sh/v1alpha1
+kind: TriggerAuthentication
+metadata:
+  name: rabbitmq-auth
+spec:
+  secretTargetRef:
+    - parameter: host
+      name: rabbitmq-credentials
+      key: url
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: rabbitmq-credentials
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := identifyAutoscalers(resources)
+
+	type expectation struct {
+		relType, source, target string
+		properties              map[string]interface{}
+	}
+	expected := []expectation{
+		{"SCALES", "web", "web", map[string]interface{}{"min": 2, "max": 10, "metrics": []string{"Resource/cpu:Utilization=80", "External/queue_length:AverageValue=30"}}},
+		{"SCALES", "legacy", "missing", map[string]interface{}{"min": 1, "max": 3, "metrics": []string{"Resource/cpu:Utilization=50"}, "unresolved": true}},
+		{"SCALES", "worker", "worker", map[string]interface{}{"min": 0, "max": 20, "metrics": []string{"rabbitmq/jobs"}}},
+		{"AUTHENTICATES_WITH", "worker", "rabbitmq-auth", map[string]interface{}{"trigger": "rabbitmq/jobs"}},
+		{"SCALES", "batch", "batch", map[string]interface{}{"min": 0, "max": 100, "metrics": []string{"cron"}}},
+		{"USES_SECRET", "rabbitmq-auth", "rabbitmq-credentials", map[string]interface{}{"parameter": "host", "key": "url"}},
+	}
+	if len(relationships) != len(expected) {
+		t.Fatalf("expected %d relationships, but got %d", len(expected), len(relationships))
+	}
+	for i, e := range expected {
+		rel := relationships[i]
+		if rel.Type != e.relType || rel.Source.Metadata.Name != e.source || rel.Target.Metadata.Name != e.target {
+			t.Errorf("expected %s %s %s, but got %s %s %s", e.source, e.relType, e.target, rel.Source.Metadata.Name, rel.Type, rel.Target.Metadata.Name)
+			continue
+		}
+		if !reflect.DeepEqual(rel.Properties, e.properties) {
+			t.Errorf("expected %s %s %s properties %v, but got %v", e.source, e.relType, e.target, e.properties, rel.Properties)
+		}
+	}
+
+	if target := relationships[0].Target; target.Kind != "Deployment" {
+		t.Errorf("expected the HPA to scale a Deployment, but got %s", target.Kind)
+	}
+	if target := relationships[2].Target; target.Kind != "Deployment" {
+		t.Errorf("expected the ScaledObject to default to scaling a Deployment, but got %s", target.Kind)
+	}
+	if target := relationships[4].Target; target.Identity().String() != "JobTemplate.keda.sh/batch" {
+		t.Errorf("expected the ScaledJob to scale its JobTemplate, but got %s", target.Identity())
+	}
+	if !resources[3].IsWorkload() {
+		t.Error("expected a ScaledJob to be a workload")
+	}
+}
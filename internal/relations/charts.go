@@ -0,0 +1,57 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"path"
+)
+
+// identifyCharts creates a Chart node for every (sub)chart that rendered a resource, with
+// RENDERS relationships to its resources and DEPENDS_ON relationships from parent to subchart.
+// Chart nodes are named by their path, e.g. "app/charts/db", since subcharts of the same name can
+// be nested at different paths; the chart property holds the chart's own name.
+func identifyCharts(resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	charts := make(map[string]*parser.Resource)
+
+	chartNode := func(chartPath string) (*parser.Resource, bool) {
+		if c, ok := charts[chartPath]; ok {
+			return c, false
+		}
+		c := &parser.Resource{
+			Kind:     "Chart",
+			Metadata: parser.Metadata{Name: chartPath},
+			Properties: map[string]interface{}{
+				"chart": path.Base(chartPath),
+			},
+		}
+		charts[chartPath] = c
+		return c, true
+	}
+
+	for _, r := range resources {
+		chain := parser.ChartChain(r.ChartPath())
+		for i, chartPath := range chain {
+			c, created := chartNode(chartPath)
+			if created && i > 0 {
+				parent := charts[chain[i-1]]
+				relationships = append(relationships, &Relationship{
+					Source: parent,
+					Target: c,
+					Type:   "DEPENDS_ON",
+				})
+			}
+		}
+		if len(chain) > 0 {
+			relationships = append(relationships, &Relationship{
+				Source: charts[chain[len(chain)-1]],
+				Target: r,
+				Type:   "RENDERS",
+				Properties: map[string]interface{}{
+					"template": r.Source,
+				},
+			})
+		}
+	}
+
+	return relationships
+}
@@ -0,0 +1,81 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyCharts(t *testing.T) {
+	resources := []*parser.Resource{
+		{Kind: "Service", Metadata: parser.Metadata{Name: "my-service"}, Source: "app/templates/service.yaml"},
+		{Kind: "Secret", Metadata: parser.Metadata{Name: "db-credentials"}, Source: "app/charts/db/templates/secret.yaml"},
+		{Kind: "ConfigMap", Metadata: parser.Metadata{Name: "db-config"}, Source: "app/charts/db/templates/configmap.yaml"},
+		{Kind: "ConfigMap", Metadata: parser.Metadata{Name: "external"}},
+	}
+
+	relationships := identifyCharts(resources)
+
+	renders := map[string]string{}
+	var dependsOn []*Relationship
+	for _, rel := range relationships {
+		switch rel.Type {
+		case "RENDERS":
+			renders[rel.Target.Metadata.Name] = rel.Source.Metadata.Name
+		case "DEPENDS_ON":
+			dependsOn = append(dependsOn, rel)
+		}
+	}
+
+	expectedRenders := map[string]string{
+		"my-service":     "app",
+		"db-credentials": "app/charts/db",
+		"db-config":      "app/charts/db",
+	}
+	if len(renders) != len(expectedRenders) {
+		t.Fatalf("expected %d RENDERS relationships, but got %v", len(expectedRenders), renders)
+	}
+	for name, chart := range expectedRenders {
+		if renders[name] != chart {
+			t.Errorf("expected %s to be rendered by %s, but got %q", name, chart, renders[name])
+		}
+	}
+
+	if len(dependsOn) != 1 {
+		t.Fatalf("expected 1 DEPENDS_ON relationship, but got %d", len(dependsOn))
+	}
+	if dependsOn[0].Source.Metadata.Name != "app" || dependsOn[0].Target.Metadata.Name != "app/charts/db" || dependsOn[0].Target.Properties["chart"] != "db" {
+		t.Errorf("expected app to depend on db, but got %s -> %s", dependsOn[0].Source.Metadata.Name, dependsOn[0].Target.Metadata.Name)
+	}
+}
+
+func TestIdentifyChartsNestedSameName(t *testing.T) {
+	resources := []*parser.Resource{
+		{Kind: "ConfigMap", Metadata: parser.Metadata{Name: "app-common"}, Source: "app/charts/common/templates/configmap.yaml"},
+		{Kind: "Secret", Metadata: parser.Metadata{Name: "redis-common"}, Source: "app/charts/redis/charts/common/templates/secret.yaml"},
+	}
+
+	charts := map[string]*parser.Resource{}
+	renders := map[string]*parser.Resource{}
+	for _, rel := range identifyCharts(resources) {
+		charts[rel.Source.Metadata.Name] = rel.Source
+		charts[rel.Target.Metadata.Name] = rel.Target
+		if rel.Type == "RENDERS" {
+			renders[rel.Target.Metadata.Name] = rel.Source
+		}
+	}
+
+	if renders["app-common"] == renders["redis-common"] {
+		t.Fatalf("expected the common subcharts at different paths to be different Chart nodes")
+	}
+	if renders["app-common"].Metadata.Name != "app/charts/common" || renders["redis-common"].Metadata.Name != "app/charts/redis/charts/common" {
+		t.Errorf("unexpected Chart nodes %s and %s", renders["app-common"].Metadata.Name, renders["redis-common"].Metadata.Name)
+	}
+	for _, c := range []*parser.Resource{renders["app-common"], renders["redis-common"]} {
+		if c.Properties["chart"] != "common" {
+			t.Errorf("expected Chart %s to be named common, but got %v", c.Metadata.Name, c.Properties["chart"])
+		}
+	}
+	if len(charts) != 6 {
+		t.Errorf("expected 4 Chart nodes and 2 resources, but got %d nodes", len(charts))
+	}
+}
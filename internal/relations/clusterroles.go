@@ -0,0 +1,58 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+var (
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"create", "delete", "deletecollection", "patch", "update"}
+)
+
+// viewRules are the rules of the default view ClusterRole: read access to the common namespaced
+// resources, except Secrets.
+var viewRules = []parser.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"configmaps", "endpoints", "persistentvolumeclaims", "pods", "pods/log", "pods/status",
+		"replicationcontrollers", "services", "serviceaccounts", "events", "limitranges", "resourcequotas", "namespaces"}, Verbs: readVerbs},
+	{APIGroups: []string{"apps"}, Resources: []string{"daemonsets", "deployments", "replicasets", "statefulsets"}, Verbs: readVerbs},
+	{APIGroups: []string{"batch"}, Resources: []string{"cronjobs", "jobs"}, Verbs: readVerbs},
+	{APIGroups: []string{"autoscaling"}, Resources: []string{"horizontalpodautoscalers"}, Verbs: readVerbs},
+	{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses", "networkpolicies"}, Verbs: readVerbs},
+	{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: readVerbs},
+}
+
+// editRules are the rules the default edit ClusterRole adds to viewRules: reading Secrets and
+// writing the common namespaced resources.
+var editRules = []parser.PolicyRule{
+	{APIGroups: []string{""}, Resources: []string{"secrets"}, Verbs: readVerbs},
+	{APIGroups: []string{""}, Resources: []string{"configmaps", "endpoints", "persistentvolumeclaims", "pods", "pods/attach", "pods/exec",
+		"pods/portforward", "replicationcontrollers", "secrets", "services", "serviceaccounts"}, Verbs: writeVerbs},
+	{APIGroups: []string{""}, Resources: []string{"serviceaccounts"}, Verbs: []string{"impersonate"}},
+	{APIGroups: []string{"apps"}, Resources: []string{"daemonsets", "deployments", "replicasets", "statefulsets"}, Verbs: writeVerbs},
+	{APIGroups: []string{"batch"}, Resources: []string{"cronjobs", "jobs"}, Verbs: writeVerbs},
+	{APIGroups: []string{"autoscaling"}, Resources: []string{"horizontalpodautoscalers"}, Verbs: writeVerbs},
+	{APIGroups: []string{"networking.k8s.io"}, Resources: []string{"ingresses", "networkpolicies"}, Verbs: writeVerbs},
+	{APIGroups: []string{"policy"}, Resources: []string{"poddisruptionbudgets"}, Verbs: writeVerbs},
+}
+
+// adminRules are the rules the default admin ClusterRole adds to editRules: managing Roles and
+// RoleBindings in the namespace.
+var adminRules = []parser.PolicyRule{
+	{APIGroups: []string{parser.RBACGroup}, Resources: []string{"roles", "rolebindings"}, Verbs: append(append([]string{}, readVerbs...), writeVerbs...)},
+}
+
+// defaultClusterRoles holds the rules of the user-facing ClusterRoles every cluster has, which
+// charts bind to without including them. The rules are condensed from the Kubernetes bootstrap
+// policy to the resources of workloads and their configuration.
+var defaultClusterRoles = map[string][]parser.PolicyRule{
+	"cluster-admin": {{APIGroups: []string{"*"}, Resources: []string{"*"}, Verbs: []string{"*"}}},
+	"admin":         concatRules(viewRules, editRules, adminRules),
+	"edit":          concatRules(viewRules, editRules),
+	"view":          viewRules,
+}
+
+func concatRules(rules ...[]parser.PolicyRule) []parser.PolicyRule {
+	var all []parser.PolicyRule
+	for _, r := range rules {
+		all = append(all, r...)
+	}
+	return all
+}
@@ -0,0 +1,118 @@
+package relations
+
+import (
+	"fmt"
+
+	"helmgraph/internal/parser"
+)
+
+// drainedKinds are the workload kinds whose pods are evicted when a node is drained, and which
+// are therefore expected to be covered by a PodDisruptionBudget.
+var drainedKinds = map[string]bool{
+	"Deployment":            true,
+	"StatefulSet":           true,
+	"ReplicaSet":            true,
+	"ReplicationController": true,
+}
+
+// identifyDisruptionBudgets links a PodDisruptionBudget to the workloads in its namespace whose
+// pod templates its selector matches with PROTECTS {minAvailable, maxUnavailable}. The
+// relationships also record the replicas of all protected workloads and whether the budget
+// blocks every voluntary eviction given that replica count. Workloads an autoscaler scales count
+// with their minimum replicas in scaled, as charts leave spec.replicas to the autoscaler.
+func identifyDisruptionBudgets(pdb *parser.Resource, resources []*parser.Resource, scaled map[*parser.Resource]int) []*Relationship {
+	spec, ok := pdb.PodDisruptionBudgetSpec()
+	if !ok {
+		return nil
+	}
+
+	var protected []*parser.Resource
+	replicas := 0
+	for _, w := range resources {
+		template, ok := w.PodTemplate()
+		if !ok || w.Metadata.Namespace != pdb.Metadata.Namespace || !spec.Selects(template.Metadata.Labels) {
+			continue
+		}
+		protected = append(protected, w)
+		switch minReplicas, ok := scaled[w]; {
+		case w.Kind == "DaemonSet":
+			// A DaemonSet runs a pod per node; spec.replicas does not apply.
+		case ok:
+			replicas += minReplicas
+		default:
+			replicas += w.Replicas()
+		}
+	}
+
+	properties := map[string]interface{}{
+		"replicas":        replicas,
+		"blocksEvictions": replicas > 0 && spec.AllowedDisruptions(replicas) == 0,
+	}
+	if spec.MinAvailable != nil {
+		properties["minAvailable"] = parser.IntOrString(*spec.MinAvailable)
+	}
+	if spec.MaxUnavailable != nil {
+		properties["maxUnavailable"] = parser.IntOrString(*spec.MaxUnavailable)
+	}
+
+	var relationships []*Relationship
+	for _, w := range protected {
+		relationships = append(relationships, &Relationship{
+			Source:     pdb,
+			Target:     w,
+			Type:       "PROTECTS",
+			Properties: properties,
+		})
+	}
+	return relationships
+}
+
+// scaledReplicas returns the minimum replicas of the workloads autoscalers scale, from their
+// SCALES relationships. If several autoscalers target a workload, the largest minimum applies.
+func scaledReplicas(relationships []*Relationship) map[*parser.Resource]int {
+	scaled := make(map[*parser.Resource]int)
+	for _, rel := range relationships {
+		if rel.Type != "SCALES" {
+			continue
+		}
+		if minReplicas, ok := rel.Properties["min"].(int); ok {
+			if current, seen := scaled[rel.Target]; !seen || minReplicas > current {
+				scaled[rel.Target] = minReplicas
+			}
+		}
+	}
+	return scaled
+}
+
+// analyzeDisruptionBudgets reports PodDisruptionBudgets that block all voluntary evictions, which
+// makes node drains hang, and drained workloads that no PodDisruptionBudget protects.
+func analyzeDisruptionBudgets(resources []*parser.Resource, relationships []*Relationship) []Finding {
+	var findings []Finding
+	protected := make(map[*parser.Resource]bool)
+	reported := make(map[*parser.Resource]bool)
+	for _, rel := range relationships {
+		if rel.Type != "PROTECTS" {
+			continue
+		}
+		protected[rel.Target] = true
+		if rel.Properties["blocksEvictions"] == true && !reported[rel.Source] {
+			reported[rel.Source] = true
+			findings = append(findings, Finding{
+				Resource: rel.Source,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("allows no voluntary evictions of its %d pod(s); node drains will block", rel.Properties["replicas"]),
+			})
+		}
+	}
+
+	for _, r := range resources {
+		if drainedKinds[r.Kind] && !protected[r] {
+			findings = append(findings, Finding{
+				Resource: r,
+				Severity: SeverityInfo,
+				Message:  "is not protected by a PodDisruptionBudget",
+			})
+		}
+	}
+	return findings
+}
@@ -0,0 +1,176 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyDisruptionBudgets(t *testing.T) {
+	manifest := `
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  maxUnavailable: 1
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: db
+spec:
+  minAvailable: 100%
+  selector:
+    matchExpressions:
+      - key: app
+        operator: In
+        values: [db]
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: web
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        app: db
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: worker
+spec:
+  template:
+    metadata:
+      labels:
+        app: worker
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	web := identifyDisruptionBudgets(resources[0], resources, nil)
+	if len(web) != 1 || web[0].Type != "PROTECTS" || web[0].Target.Metadata.Name != "web" {
+		t.Fatalf("expected PodDisruptionBudget web to protect Deployment web, but got %v", web)
+	}
+	if web[0].Properties["maxUnavailable"] != 1 || web[0].Properties["blocksEvictions"] != false || web[0].Properties["replicas"] != 3 {
+		t.Errorf("unexpected properties %v", web[0].Properties)
+	}
+
+	db := identifyDisruptionBudgets(resources[1], resources, nil)
+	if len(db) != 1 || db[0].Target.Metadata.Name != "db" {
+		t.Fatalf("expected PodDisruptionBudget db to protect StatefulSet db, but got %v", db)
+	}
+	if db[0].Properties["minAvailable"] != "100%" || db[0].Properties["blocksEvictions"] != true {
+		t.Errorf("unexpected properties %v", db[0].Properties)
+	}
+
+	findings := Analyze(resources, Identify(resources))
+	expected := []string{
+		"warning: PodDisruptionBudget.policy/default/db allows no voluntary evictions of its 2 pod(s); node drains will block",
+		"info: Deployment.apps/default/worker is not protected by a PodDisruptionBudget",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, but got %v", len(expected), findings)
+	}
+	for i, e := range expected {
+		if findings[i].String() != e {
+			t.Errorf("expected finding %q, but got %q", e, findings[i].String())
+		}
+	}
+}
+
+func TestIdentifyDisruptionBudgetsAutoscaled(t *testing.T) {
+	manifest := `
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+---
+apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: web
+spec:
+  scaleTargetRef:
+    apiVersion: apps/v1
+    kind: Deployment
+    name: web
+  minReplicas: 3
+  maxReplicas: 10
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: agent
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: agent
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+spec:
+  template:
+    metadata:
+      labels:
+        app: agent
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	scaled := scaledReplicas(identifyAutoscalers(resources))
+	web := identifyDisruptionBudgets(resources[0], resources, scaled)
+	if len(web) != 1 || web[0].Properties["replicas"] != 3 || web[0].Properties["blocksEvictions"] != false {
+		t.Fatalf("expected PodDisruptionBudget web to count the 3 minimum replicas of the HorizontalPodAutoscaler, but got %v", web)
+	}
+	agent := identifyDisruptionBudgets(resources[3], resources, scaled)
+	if len(agent) != 1 || agent[0].Properties["replicas"] != 0 || agent[0].Properties["blocksEvictions"] != false {
+		t.Fatalf("expected PodDisruptionBudget agent not to count DaemonSet replicas, but got %v", agent)
+	}
+
+	for _, f := range Analyze(resources, Identify(resources)) {
+		if f.Severity == SeverityWarning {
+			t.Errorf("unexpected finding %s", f)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// identifyGateways links Gateway API routes to the Gateways they attach to with ATTACHES_TO and
+// to the backends they forward to with ROUTES_TO, and Gateways to the certificate Secrets of
+// their listeners with USES_TLS_SECRET. Attachments not allowed by the Gateway's listeners and
+// cross-namespace references not permitted by a ReferenceGrant are flagged with allowed: false.
+func identifyGateways(resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	missing := placeholders{}
+
+	for _, r := range resources {
+		if spec, ok := r.RouteSpec(); ok {
+			for _, ref := range spec.ParentRefs {
+				properties := map[string]interface{}{}
+				if ref.SectionName != "" {
+					properties["sectionName"] = ref.SectionName
+				}
+				if ref.Port != 0 {
+					properties["port"] = ref.Port
+				}
+				targets, resolved := missing.resolve(resources, ref.Identity(r, parser.GatewayGroup, "Gateway"))
+				if !resolved {
+					properties["unresolved"] = true
+				} else if !routeAllowed(r, targets[0], ref, resources) {
+					properties["allowed"] = false
+				}
+				for _, g := range targets {
+					relationships = append(relationships, &Relationship{
+						Source:     r,
+						Target:     g,
+						Type:       "ATTACHES_TO",
+						Properties: properties,
+					})
+				}
+			}
+			for i, rule := range spec.Rules {
+				for _, ref := range rule.BackendRefs {
+					weight := 1
+					if ref.Weight != nil {
+						weight = *ref.Weight
+					}
+					properties := map[string]interface{}{
+						"rule":   i,
+						"weight": weight,
+					}
+					if ref.Port != 0 {
+						properties["port"] = ref.Port
+					}
+					relationships = append(relationships, gatewayReference(r, ref.Identity(r, "", "Service"), "ROUTES_TO", properties, resources, missing)...)
+				}
+			}
+		}
+		if spec, ok := r.GatewaySpec(); ok {
+			for _, l := range spec.Listeners {
+				for _, ref := range l.TLS.CertificateRefs {
+					relationships = append(relationships, gatewayReference(r, ref.Identity(r, "", "Secret"), "USES_TLS_SECRET", map[string]interface{}{
+						"listener": l.Name,
+					}, resources, missing)...)
+				}
+			}
+		}
+	}
+
+	return relationships
+}
+
+// gatewayReference returns the relationships of type relType from a route or Gateway to the
+// resource it references, checking cross-namespace references against ReferenceGrants.
+func gatewayReference(from *parser.Resource, id parser.Identity, relType string, properties map[string]interface{}, resources []*parser.Resource, missing placeholders) []*Relationship {
+	if id.Namespace != "" && id.Namespace != from.Metadata.Namespace {
+		properties["crossNamespace"] = true
+		if grant := referenceGrant(from, id, resources); grant != nil {
+			properties["referenceGrant"] = grant.Metadata.Name
+		} else {
+			properties["allowed"] = false
+		}
+	}
+
+	targets, resolved := missing.resolve(resources, id)
+	if !resolved {
+		properties["unresolved"] = true
+	}
+
+	var relationships []*Relationship
+	for _, t := range targets {
+		relationships = append(relationships, &Relationship{
+			Source:     from,
+			Target:     t,
+			Type:       relType,
+			Properties: properties,
+		})
+	}
+	return relationships
+}
+
+// referenceGrant returns the ReferenceGrant in the namespace of to that permits from to refer to it, if any.
+func referenceGrant(from *parser.Resource, to parser.Identity, resources []*parser.Resource) *parser.Resource {
+	for _, g := range resources {
+		if g.Metadata.Namespace != to.Namespace {
+			continue
+		}
+		if spec, ok := g.ReferenceGrantSpec(); ok && spec.Permits(from, to) {
+			return g
+		}
+	}
+	return nil
+}
+
+// routeAllowed reports whether a listener of the Gateway selected by ref allows routes from the
+// namespace of route. Listeners allow routes from their own namespace unless allowedRoutes says otherwise.
+func routeAllowed(route, gateway *parser.Resource, ref parser.ParentReference, resources []*parser.Resource) bool {
+	spec, ok := gateway.GatewaySpec()
+	if !ok {
+		return true
+	}
+	for _, l := range spec.Listeners {
+		if (ref.SectionName != "" && l.Name != ref.SectionName) || (ref.Port != 0 && l.Port != ref.Port) {
+			continue
+		}
+		switch l.AllowedRoutes.Namespaces.From {
+		case "All":
+			return true
+		case "Selector":
+			if l.AllowedRoutes.Namespaces.Selector != nil && namespaceSelected(*l.AllowedRoutes.Namespaces.Selector, route.Metadata.Namespace, resources) {
+				return true
+			}
+		default:
+			if route.Metadata.Namespace == gateway.Metadata.Namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyGateways(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: apps
+  labels:
+    team: apps
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: Gateway
+metadata:
+  name: gw
+  namespace: infra
+spec:
+  gatewayClassName: envoy
+  listeners:
+    - name: internal
+      port: 80
+      protocol: HTTP
+    - name: shared
+      port: 443
+      protocol: HTTPS
+      allowedRoutes:
+        namespaces:
+          from: Selector
+          selector:
+            matchLabels:
+              team: apps
+      tls:
+        certificateRefs:
+          - name: gw-tls
+          - name: wildcard
+            namespace: certs
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: gw-tls
+  namespace: infra
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: wildcard
+  namespace: certs
+---
+apiVersion: gateway.networking.k8s.io/v1beta1
+kind: ReferenceGrant
+metadata:
+  name: allow-gateways
+  namespace: certs
+spec:
+  from:
+    - group: gateway.networking.k8s.io
+      kind: Gateway
+      namespace: infra
+  to:
+    - group: ""
+      kind: Secret
+---
+apiVersion: gateway.networking.k8s.io/v1
+kind: HTTPRoute
+metadata:
+  name: web
+  namespace: apps
+spec:
+  parentRefs:
+    - name: gw
+      namespace: infra
+      sectionName: shared
+    - name: gw
+      namespace: infra
+      sectionName: internal
+  rules:
+    - backendRefs:
+        - name: web
+          port: 8080
+          weight: 90
+        - name: web-canary
+          port: 8080
+          weight: 10
+    - backendRefs:
+        - name: api
+          namespace: backend
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: apps
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: backend
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := identifyGateways(resources)
+
+	type expectation struct {
+		relType, source, target string
+		properties              map[string]interface{}
+	}
+	expected := []expectation{
+		{"USES_TLS_SECRET", "gw", "gw-tls", map[string]interface{}{"listener": "shared"}},
+		{"USES_TLS_SECRET", "gw", "wildcard", map[string]interface{}{"listener": "shared", "crossNamespace": true, "referenceGrant": "allow-gateways"}},
+		{"ATTACHES_TO", "web", "gw", map[string]interface{}{"sectionName": "shared"}},
+		{"ATTACHES_TO", "web", "gw", map[string]interface{}{"sectionName": "internal", "allowed": false}},
+		{"ROUTES_TO", "web", "web", map[string]interface{}{"rule": 0, "weight": 90, "port": 8080}},
+		{"ROUTES_TO", "web", "web-canary", map[string]interface{}{"rule": 0, "weight": 10, "port": 8080, "unresolved": true}},
+		{"ROUTES_TO", "web", "api", map[string]interface{}{"rule": 1, "weight": 1, "crossNamespace": true, "allowed": false}},
+	}
+	if len(relationships) != len(expected) {
+		t.Fatalf("expected %d relationships, but got %d", len(expected), len(relationships))
+	}
+	for i, e := range expected {
+		rel := relationships[i]
+		if rel.Type != e.relType || rel.Source.Metadata.Name != e.source || rel.Target.Metadata.Name != e.target {
+			t.Errorf("expected %s %s %s, but got %s %s %s", e.source, e.relType, e.target, rel.Source.Metadata.Name, rel.Type, rel.Target.Metadata.Name)
+			continue
+		}
+		if len(rel.Properties) != len(e.properties) {
+			t.Errorf("expected %s %s %s properties %v, but got %v", e.source, e.relType, e.target, e.properties, rel.Properties)
+			continue
+		}
+		for k, v := range e.properties {
+			if rel.Properties[k] != v {
+				t.Errorf("expected %s %s %s property %s to be %v, but got %v", e.source, e.relType, e.target, k, v, rel.Properties[k])
+			}
+		}
+	}
+
+	canary := relationships[5].Target
+	if canary.Properties["placeholder"] != true || canary.Metadata.Namespace != "apps" || canary.Kind != "Service" {
+		t.Errorf("expected a placeholder Service apps/web-canary, but got %+v", canary)
+	}
+}
@@ -0,0 +1,117 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// kindOrder ranks kinds by Helm's install order; kinds not in it rank after all of them.
+var kindOrder = func() map[string]int {
+	order := make(map[string]int, len(releaseutil.InstallOrder))
+	for i, kind := range releaseutil.InstallOrder {
+		order[kind] = i
+	}
+	return order
+}()
+
+// hookPhases are the lifecycle phases whose hook ordering is modelled, in the order Helm runs
+// them around applying the release manifest.
+var hookPhases = []struct {
+	pre, post string
+}{
+	{"pre-install", "post-install"},
+	{"pre-upgrade", "post-upgrade"},
+}
+
+// identifyHooks chains the hooks of every install and upgrade phase with PRECEDES relationships
+// in the order Helm executes them: by ascending weight, then by name, then by kind in install
+// order. When the resources include the Release node, it stands for applying the release
+// manifest, between the pre and post hooks.
+func identifyHooks(resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+
+	var release *parser.Resource
+	for _, r := range resources {
+		if r.Kind == "Release" {
+			release = r
+			break
+		}
+	}
+
+	for _, phases := range hookPhases {
+		pre := hooksFor(resources, phases.pre)
+		post := hooksFor(resources, phases.post)
+
+		relationships = append(relationships, chainHooks(pre, phases.pre)...)
+		relationships = append(relationships, chainHooks(post, phases.post)...)
+		if release == nil {
+			continue
+		}
+		if len(pre) > 0 {
+			relationships = append(relationships, &Relationship{
+				Source:     pre[len(pre)-1],
+				Target:     release,
+				Type:       "PRECEDES",
+				Properties: map[string]interface{}{"phase": phases.pre},
+			})
+		}
+		if len(post) > 0 {
+			relationships = append(relationships, &Relationship{
+				Source:     release,
+				Target:     post[0],
+				Type:       "PRECEDES",
+				Properties: map[string]interface{}{"phase": phases.post},
+			})
+		}
+	}
+
+	return relationships
+}
+
+// hooksFor returns the hooks that run on phase, in execution order.
+func hooksFor(resources []*parser.Resource, phase string) []*parser.Resource {
+	var hooks []*parser.Resource
+	for _, r := range resources {
+		if h := r.Hook(); h != nil && h.HasPhase(phase) {
+			hooks = append(hooks, r)
+		}
+	}
+	sort.SliceStable(hooks, func(i, j int) bool {
+		wi, wj := hooks[i].Hook().Weight, hooks[j].Hook().Weight
+		if wi != wj {
+			return wi < wj
+		}
+		if ni, nj := hooks[i].Metadata.Name, hooks[j].Metadata.Name; ni != nj {
+			return ni < nj
+		}
+		return installRank(hooks[i].Kind) < installRank(hooks[j].Kind)
+	})
+	return hooks
+}
+
+// installRank returns the position of kind in Helm's install order, or len(InstallOrder) for
+// kinds Helm does not know.
+func installRank(kind string) int {
+	if rank, ok := kindOrder[kind]; ok {
+		return rank
+	}
+	return len(releaseutil.InstallOrder)
+}
+
+// chainHooks links each hook to the next one with a PRECEDES relationship.
+func chainHooks(hooks []*parser.Resource, phase string) []*Relationship {
+	var relationships []*Relationship
+	for i := 1; i < len(hooks); i++ {
+		relationships = append(relationships, &Relationship{
+			Source: hooks[i-1],
+			Target: hooks[i],
+			Type:   "PRECEDES",
+			Properties: map[string]interface{}{
+				"phase": phase,
+			},
+		})
+	}
+	return relationships
+}
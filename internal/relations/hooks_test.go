@@ -0,0 +1,77 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func hookResource(kind, name, phases, weight string) *parser.Resource {
+	return &parser.Resource{
+		Kind: kind,
+		Metadata: parser.Metadata{
+			Name: name,
+			Annotations: map[string]string{
+				parser.HookAnnotation:       phases,
+				parser.HookWeightAnnotation: weight,
+			},
+		},
+	}
+}
+
+func TestIdentifyHooks(t *testing.T) {
+	release := &parser.Resource{Kind: "Release", Metadata: parser.Metadata{Name: "my-release"}}
+	resources := []*parser.Resource{
+		hookResource("Job", "db-migrate", "pre-install,pre-upgrade", "0"),
+		hookResource("Secret", "db-credentials", "pre-install", "-5"),
+		hookResource("Job", "smoke-test", "post-install", ""),
+		{Kind: "Deployment", Metadata: parser.Metadata{Name: "my-deployment"}},
+		release,
+	}
+
+	relationships := identifyHooks(resources)
+
+	var got []string
+	for _, rel := range relationships {
+		if rel.Type != "PRECEDES" {
+			t.Errorf("unexpected relationship type %s", rel.Type)
+		}
+		got = append(got, rel.Properties["phase"].(string)+": "+rel.Source.Metadata.Name+" -> "+rel.Target.Metadata.Name)
+	}
+
+	expected := []string{
+		"pre-install: db-credentials -> db-migrate",
+		"pre-install: db-migrate -> my-release",
+		"post-install: my-release -> smoke-test",
+		"pre-upgrade: db-migrate -> my-release",
+	}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, but got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %v, but got %v", expected, got)
+			break
+		}
+	}
+}
+
+func TestIdentifyHooksKindOrder(t *testing.T) {
+	resources := []*parser.Resource{
+		hookResource("Job", "a-migrate", "pre-install", "1"),
+		hookResource("Widget", "a-widget", "pre-install", "1"),
+		hookResource("ConfigMap", "b-config", "pre-install", "1"),
+		hookResource("ConfigMap", "a-config", "pre-install", "1"),
+	}
+
+	var got []string
+	for _, h := range hooksFor(resources, "pre-install") {
+		got = append(got, h.Metadata.Name)
+	}
+
+	expected := []string{"a-config", "a-migrate", "a-widget", "b-config"}
+	for i := range expected {
+		if i >= len(got) || got[i] != expected[i] {
+			t.Fatalf("expected %v, but got %v", expected, got)
+		}
+	}
+}
@@ -10,149 +10,82 @@ type Relationship struct {
 	Properties map[string]interface{}
 }
 
-// Identify identifies relationships between Kubernetes resources.
+// Options controls optional parts of relationship identification.
+type Options struct {
+	// ExpandClaims creates a PersistentVolumeClaim node for every replica of a StatefulSet
+	// volume claim template.
+	ExpandClaims bool
+}
+
+// Identify identifies relationships between Kubernetes resources with the default options.
 func Identify(resources []*parser.Resource) []*Relationship {
+	return IdentifyWithOptions(resources, Options{})
+}
+
+// IdentifyWithOptions identifies relationships between Kubernetes resources.
+func IdentifyWithOptions(resources []*parser.Resource, opts Options) []*Relationship {
 	var relationships []*Relationship
+	autoscalers := identifyAutoscalers(resources)
+	scaled := scaledReplicas(autoscalers)
 
 	for _, r := range resources {
 		if r.Kind == "Service" {
-			for _, d := range resources {
-				if d.Kind == "Deployment" {
-					if selectorsMatch(r.Spec.Selector, d.Metadata.Labels) {
-						relationships = append(relationships, &Relationship{
-							Source: r,
-							Target: d,
-							Type:   "SELECTS",
-							Properties: map[string]interface{}{
-								"selector_labels": r.Spec.Selector,
-							},
-						})
-					}
-				}
-			}
+			relationships = append(relationships, identifyServiceSelectors(r, resources)...)
+		}
+		if r.Kind == "Ingress" {
+			relationships = append(relationships, identifyIngress(r, resources)...)
 		}
-		if r.Kind == "Deployment" {
-			for _, v := range r.Spec.Template.Spec.Volumes {
-				if v.ConfigMap.Name != "" {
-					for _, c := range resources {
-						if c.Kind == "ConfigMap" && c.Metadata.Name == v.ConfigMap.Name {
-							relationships = append(relationships, &Relationship{
-								Source: r,
-								Target: c,
-								Type:   "USES_CONFIG",
-								Properties: map[string]interface{}{
-									"volume": v.Name,
-								},
-							})
-						}
-					}
-				}
-				if v.Secret.SecretName != "" {
-					for _, s := range resources {
-						if s.Kind == "Secret" && s.Metadata.Name == v.Secret.SecretName {
-							relationships = append(relationships, &Relationship{
-								Source: r,
-								Target: s,
-								Type:   "USES_SECRET",
-								Properties: map[string]interface{}{
-									"volume": v.Name,
-								},
-							})
-						}
-					}
-				}
-			}
-			for _, c := range r.Spec.Template.Spec.Containers {
-				for _, e := range c.EnvFrom {
-					if e.ConfigMapRef.Name != "" {
-						for _, cm := range resources {
-							if cm.Kind == "ConfigMap" && cm.Metadata.Name == e.ConfigMapRef.Name {
-								relationships = append(relationships, &Relationship{
-									Source: r,
-									Target: cm,
-									Type:   "USES_CONFIG",
-									Properties: map[string]interface{}{
-										"envFrom": true,
-									},
-								})
-							}
-						}
-					}
-					if e.SecretRef.Name != "" {
-						for _, s := range resources {
-							if s.Kind == "Secret" && s.Metadata.Name == e.SecretRef.Name {
-								relationships = append(relationships, &Relationship{
-									Source: r,
-									Target: s,
-									Type:   "USES_SECRET",
-									Properties: map[string]interface{}{
-										"envFrom": true,
-									},
-								})
-							}
-						}
-					}
-				}
-				for _, e := range c.Env {
-					if e.ValueFrom.ConfigMapKeyRef.Name != "" {
-						for _, cm := range resources {
-							if cm.Kind == "ConfigMap" && cm.Metadata.Name == e.ValueFrom.ConfigMapKeyRef.Name {
-								relationships = append(relationships, &Relationship{
-									Source: r,
-									Target: cm,
-									Type:   "USES_CONFIG",
-									Properties: map[string]interface{}{
-										"env_var_name": e.Name,
-									},
-								})
-							}
-						}
-					}
-					if e.ValueFrom.SecretKeyRef.Name != "" {
-						for _, s := range resources {
-							if s.Kind == "Secret" && s.Metadata.Name == e.ValueFrom.SecretKeyRef.Name {
-								relationships = append(relationships, &Relationship{
-									Source: r,
-									Target: s,
-									Type:   "USES_SECRET",
-									Properties: map[string]interface{}{
-										"env_var_name": e.Name,
-									},
-								})
-							}
-						}
-					}
-				}
-			}
+		if r.Kind == "PodDisruptionBudget" {
+			relationships = append(relationships, identifyDisruptionBudgets(r, resources, scaled)...)
 		}
-		if r.Kind == "StatefulSet" {
-			for _, pvc := range r.Spec.VolumeClaimTemplates {
-				for _, p := range resources {
-					if p.Kind == "PersistentVolumeClaim" && p.Metadata.Name == pvc.Metadata.Name {
-						relationships = append(relationships, &Relationship{
-							Source: r,
-							Target: p,
-							Type:   "USES_PVC",
-						})
-					}
-				}
-			}
+		if template, ok := r.PodTemplate(); ok {
+			relationships = append(relationships, identifyPodReferences(r, &template.Spec, resources)...)
+			relationships = append(relationships, identifyAffinity(r, &template.Spec, resources)...)
 		}
 	}
 
+	relationships = append(relationships, identifyCharts(resources)...)
+	relationships = append(relationships, identifyHooks(resources)...)
+	relationships = append(relationships, identifyGateways(resources)...)
+	relationships = append(relationships, autoscalers...)
+	policies, _ := identifyNetworkPolicies(resources)
+	relationships = append(relationships, policies...)
+	relationships = append(relationships, identifyRBAC(resources)...)
+	relationships = append(relationships, identifyStatefulSets(resources, opts)...)
+	relationships = append(relationships, identifyStorage(resources)...)
+	relationships = append(relationships, identifyPullSecrets(resources)...)
+
 	return relationships
 }
 
-func selectorsMatch(serviceSelector, deploymentLabels map[string]string) bool {
-	if len(serviceSelector) == 0 {
-		return false
+// findResources returns the resources with the given identity.
+func findResources(resources []*parser.Resource, id parser.Identity) []*parser.Resource {
+	var found []*parser.Resource
+	for _, r := range resources {
+		if r.Identity() == id {
+			found = append(found, r)
+		}
 	}
+	return found
+}
 
-	for k, v := range serviceSelector {
-		if labelValue, ok := deploymentLabels[k]; !ok || labelValue != v {
-			return false
+// namespaceNameLabel is the label Kubernetes sets on every Namespace to its name.
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+// namespaceSelected reports whether a namespace selector selects namespace. The selector is
+// matched against the labels of the Namespace resource in the manifest, if any, together with the
+// kubernetes.io/metadata.name label Kubernetes sets on every namespace; an empty selector selects
+// every namespace.
+func namespaceSelected(selector parser.LabelSelector, namespace string, resources []*parser.Resource) bool {
+	if selector.Empty() {
+		return true
+	}
+	labels := make(map[string]string)
+	for _, n := range findResources(resources, parser.Identity{Kind: "Namespace", Name: namespace}) {
+		for k, v := range n.Metadata.Labels {
+			labels[k] = v
 		}
 	}
-
-	return true
+	labels[namespaceNameLabel] = namespace
+	return selector.Matches(labels)
 }
@@ -12,17 +12,8 @@ func TestIdentify(t *testing.T) {
 			Metadata: parser.Metadata{
 				Name: "my-service",
 			},
-			Spec: struct {
-				Selector parser.Selector `yaml:"selector"`
-				Template struct {
-					Spec struct {
-						Containers []parser.Container `yaml:"containers"`
-						Volumes    []parser.Volume    `yaml:"volumes"`
-					} `yaml:"spec"`
-				} `yaml:"template"`
-				VolumeClaimTemplates []parser.PersistentVolumeClaim `yaml:"volumeClaimTemplates"`
-			}{
-				Selector: map[string]string{"app": "my-app"},
+			Spec: parser.ResourceSpec{
+				Selector: parser.LabelSelector{MatchLabels: map[string]string{"app": "my-app"}},
 			},
 		},
 		{
@@ -31,26 +22,12 @@ func TestIdentify(t *testing.T) {
 				Name:   "my-deployment",
 				Labels: map[string]string{"app": "my-app"},
 			},
-			Spec: struct {
-				Selector parser.Selector `yaml:"selector"`
-				Template struct {
-					Spec struct {
-						Containers []parser.Container `yaml:"containers"`
-						Volumes    []parser.Volume    `yaml:"volumes"`
-					} `yaml:"spec"`
-				} `yaml:"template"`
-				VolumeClaimTemplates []parser.PersistentVolumeClaim `yaml:"volumeClaimTemplates"`
-			}{
-				Template: struct {
-					Spec struct {
-						Containers []parser.Container `yaml:"containers"`
-						Volumes    []parser.Volume    `yaml:"volumes"`
-					} `yaml:"spec"`
-				}{
-					Spec: struct {
-						Containers []parser.Container `yaml:"containers"`
-						Volumes    []parser.Volume    `yaml:"volumes"`
-					}{
+			Spec: parser.ResourceSpec{
+				Template: parser.PodTemplateSpec{
+					Metadata: parser.Metadata{
+						Labels: map[string]string{"app": "my-app"},
+					},
+					Spec: parser.PodSpec{
 						Volumes: []parser.Volume{
 							{
 								Name: "config",
@@ -89,8 +66,8 @@ func TestIdentify(t *testing.T) {
 
 	relationships := Identify(resources)
 
-	if len(relationships) != 3 {
-		t.Fatalf("expected 3 relationships, but got %d", len(relationships))
+	if len(relationships) != 4 {
+		t.Fatalf("expected 4 relationships, but got %d", len(relationships))
 	}
 
 	// Add a StatefulSet and PVC for testing
@@ -99,16 +76,7 @@ func TestIdentify(t *testing.T) {
 		Metadata: parser.Metadata{
 			Name: "my-statefulset",
 		},
-		Spec: struct {
-			Selector parser.Selector `yaml:"selector"`
-			Template struct {
-				Spec struct {
-					Containers []parser.Container `yaml:"containers"`
-					Volumes    []parser.Volume    `yaml:"volumes"`
-				} `yaml:"spec"`
-			} `yaml:"template"`
-			VolumeClaimTemplates []parser.PersistentVolumeClaim `yaml:"volumeClaimTemplates"`
-		}{
+		Spec: parser.ResourceSpec{
 			VolumeClaimTemplates: []parser.PersistentVolumeClaim{
 				{
 					Metadata: parser.Metadata{
@@ -126,7 +94,88 @@ func TestIdentify(t *testing.T) {
 
 	relationships = Identify(resources)
 
-	if len(relationships) != 4 {
-		t.Fatalf("expected 4 relationships, but got %d", len(relationships))
+	if len(relationships) != 6 {
+		t.Fatalf("expected 6 relationships, but got %d", len(relationships))
+	}
+}
+
+func TestIdentifyNamespaces(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  selector:
+    app: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-a
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      volumes:
+        - name: config
+          configMap:
+            name: web-config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: team-a
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: team-b
+spec:
+  selector:
+    app: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: team-b
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      volumes:
+        - name: config
+          configMap:
+            name: web-config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+  namespace: team-b
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := Identify(resources)
+
+	if len(relationships) != 6 {
+		t.Fatalf("expected 6 relationships, but got %d", len(relationships))
+	}
+	for _, rel := range relationships {
+		if rel.Source.Metadata.Namespace != rel.Target.Metadata.Namespace {
+			t.Errorf("expected %s not to link across namespaces to %s", rel.Source.Identity(), rel.Target.Identity())
+		}
 	}
 }
@@ -0,0 +1,72 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// identifyIngress links an Ingress to the Services its rules and default backend route to,
+// and to the Secrets holding its TLS certificates.
+func identifyIngress(ingress *parser.Resource, resources []*parser.Resource) []*Relationship {
+	spec, ok := ingress.IngressSpec()
+	if !ok {
+		return nil
+	}
+
+	var relationships []*Relationship
+	if spec.DefaultBackend != nil {
+		relationships = append(relationships, ingressBackend(ingress, spec.DefaultBackend, map[string]interface{}{
+			"defaultBackend": true,
+		}, resources)...)
+	}
+	for _, rule := range spec.Rules {
+		for _, p := range rule.HTTP.Paths {
+			properties := map[string]interface{}{
+				"host": rule.Host,
+				"path": p.Path,
+			}
+			if p.PathType != "" {
+				properties["pathType"] = p.PathType
+			}
+			relationships = append(relationships, ingressBackend(ingress, &p.Backend, properties, resources)...)
+		}
+	}
+
+	for _, tls := range spec.TLS {
+		if tls.SecretName == "" {
+			continue
+		}
+		for _, s := range findResources(resources, ingress.Reference("", "Secret", tls.SecretName)) {
+			relationships = append(relationships, &Relationship{
+				Source: ingress,
+				Target: s,
+				Type:   "USES_TLS_SECRET",
+				Properties: map[string]interface{}{
+					"hosts": tls.Hosts,
+				},
+			})
+		}
+	}
+
+	return relationships
+}
+
+// ingressBackend returns the ROUTES_TO relationships from an Ingress to the Service or
+// resource of a backend.
+func ingressBackend(ingress *parser.Resource, backend *parser.IngressBackend, properties map[string]interface{}, resources []*parser.Resource) []*Relationship {
+	var targets []*parser.Resource
+	if name, port, ok := backend.ServiceRef(); ok {
+		properties["port"] = port
+		targets = findResources(resources, ingress.Reference("", "Service", name))
+	} else if backend.Resource != nil {
+		targets = findResources(resources, ingress.Reference(backend.Resource.APIGroup, backend.Resource.Kind, backend.Resource.Name))
+	}
+
+	var relationships []*Relationship
+	for _, t := range targets {
+		relationships = append(relationships, &Relationship{
+			Source:     ingress,
+			Target:     t,
+			Type:       "ROUTES_TO",
+			Properties: properties,
+		})
+	}
+	return relationships
+}
@@ -0,0 +1,101 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyIngress(t *testing.T) {
+	manifest := `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  defaultBackend:
+    service:
+      name: fallback
+      port:
+        number: 8080
+  tls:
+    - hosts: [example.com]
+      secretName: example-tls
+  rules:
+    - host: example.com
+      http:
+        paths:
+          - path: /api
+            pathType: Prefix
+            backend:
+              service:
+                name: api
+                port:
+                  name: http
+---
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: legacy
+spec:
+  rules:
+    - host: legacy.example.com
+      http:
+        paths:
+          - path: /
+            backend:
+              serviceName: api
+              servicePort: 80
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: fallback
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: example-tls
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := identifyIngress(resources[0], resources)
+	if len(relationships) != 3 {
+		t.Fatalf("expected 3 relationships, but got %d", len(relationships))
+	}
+
+	fallback := relationships[0]
+	if fallback.Type != "ROUTES_TO" || fallback.Target.Metadata.Name != "fallback" || fallback.Properties["port"] != 8080 || fallback.Properties["defaultBackend"] != true {
+		t.Errorf("unexpected default backend relationship %s to %s %v", fallback.Type, fallback.Target.Metadata.Name, fallback.Properties)
+	}
+
+	api := relationships[1]
+	if api.Type != "ROUTES_TO" || api.Target.Metadata.Name != "api" {
+		t.Fatalf("expected a route to Service api, but got %s to %s", api.Type, api.Target.Metadata.Name)
+	}
+	for k, v := range map[string]interface{}{"host": "example.com", "path": "/api", "pathType": "Prefix", "port": "http"} {
+		if api.Properties[k] != v {
+			t.Errorf("expected %s to be %v, but got %v", k, v, api.Properties[k])
+		}
+	}
+
+	tls := relationships[2]
+	if tls.Type != "USES_TLS_SECRET" || tls.Target.Metadata.Name != "example-tls" {
+		t.Errorf("expected the Ingress to use Secret example-tls, but got %s to %s", tls.Type, tls.Target.Metadata.Name)
+	}
+
+	legacy := identifyIngress(resources[1], resources)
+	if len(legacy) != 1 {
+		t.Fatalf("expected 1 legacy relationship, but got %d", len(legacy))
+	}
+	if legacy[0].Target.Metadata.Name != "api" || legacy[0].Properties["port"] != 80 || legacy[0].Properties["host"] != "legacy.example.com" {
+		t.Errorf("unexpected legacy relationship to %s %v", legacy[0].Target.Metadata.Name, legacy[0].Properties)
+	}
+}
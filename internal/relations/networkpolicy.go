@@ -0,0 +1,206 @@
+package relations
+
+import (
+	"strings"
+
+	"helmgraph/internal/parser"
+)
+
+// anyCIDR stands for every address outside the cluster in rules without peers.
+const anyCIDR = "0.0.0.0/0"
+
+// identifyNetworkPolicies evaluates the NetworkPolicies against the workloads in the manifest.
+// Every policy ISOLATES the workloads its pod selector matches, for each direction it controls,
+// and each of its rules ALLOWS_TRAFFIC {direction, ports, policy} in the direction traffic flows:
+// from the rule's peers to the selected workloads for ingress, and the other way round for
+// egress. IP blocks are ExternalCIDR nodes; a rule without peers allows traffic from or to every
+// workload and anyCIDR. A selector peer that matches no workload in the manifest, such as pods of
+// another namespace, is a NetworkPeer placeholder named after its selectors. It also returns the
+// directions in which each workload is isolated with no rule allowing any traffic.
+func identifyNetworkPolicies(resources []*parser.Resource) ([]*Relationship, map[*parser.Resource][]string) {
+	var relationships []*Relationship
+	cidrs := make(map[string]*parser.Resource)
+	allowed := make(map[*parser.Resource]map[string]bool)
+	isolated := make(map[*parser.Resource]map[string]bool)
+	missing := placeholders{}
+
+	cidrNode := func(cidr string) *parser.Resource {
+		if c, ok := cidrs[cidr]; ok {
+			return c
+		}
+		c := &parser.Resource{
+			Kind:     "ExternalCIDR",
+			Metadata: parser.Metadata{Name: cidr},
+		}
+		cidrs[cidr] = c
+		return c
+	}
+
+	for _, policy := range resources {
+		spec, ok := policy.NetworkPolicySpec()
+		if !ok {
+			continue
+		}
+		selected := selectedWorkloads(policy, spec.PodSelector, resources)
+
+		for _, policyType := range []string{parser.PolicyTypeIngress, parser.PolicyTypeEgress} {
+			if !spec.HasPolicyType(policyType) {
+				continue
+			}
+			direction := strings.ToLower(policyType)
+			rules := spec.Rules(policyType)
+			for _, w := range selected {
+				relationships = append(relationships, &Relationship{
+					Source: policy,
+					Target: w,
+					Type:   "ISOLATES",
+					Properties: map[string]interface{}{
+						"direction": direction,
+					},
+				})
+				mark(isolated, w, direction)
+				if len(rules) > 0 {
+					mark(allowed, w, direction)
+				}
+			}
+
+			for _, rule := range rules {
+				properties := map[string]interface{}{
+					"direction": direction,
+					"ports":     rule.PortNames(),
+					"policy":    policy.Metadata.Name,
+				}
+
+				var peers []*parser.Resource
+				peerProperties := make(map[*parser.Resource]map[string]interface{})
+				if len(rule.Peers()) == 0 {
+					for _, r := range resources {
+						if r.IsWorkload() {
+							peers = append(peers, r)
+						}
+					}
+					peers = append(peers, cidrNode(anyCIDR))
+				}
+				for _, peer := range rule.Peers() {
+					if peer.IPBlock != nil {
+						c := cidrNode(peer.IPBlock.CIDR)
+						peers = append(peers, c)
+						if len(peer.IPBlock.Except) > 0 {
+							peerProperties[c] = map[string]interface{}{"except": peer.IPBlock.Except}
+						}
+						continue
+					}
+					workloads := peerWorkloads(policy, peer, resources)
+					if len(workloads) == 0 && (peer.PodSelector != nil || peer.NamespaceSelector != nil) {
+						namespace := policy.Metadata.Namespace
+						if peer.NamespaceSelector != nil {
+							namespace = ""
+						}
+						placeholder, _ := missing.resolve(nil, parser.Identity{Kind: "NetworkPeer", Namespace: namespace, Name: peer.String()})
+						workloads = placeholder
+						peerProperties[placeholder[0]] = map[string]interface{}{"unresolved": true}
+					}
+					peers = append(peers, workloads...)
+				}
+
+				for _, w := range selected {
+					for _, p := range peers {
+						rel := &Relationship{
+							Type:       "ALLOWS_TRAFFIC",
+							Properties: withProperties(properties, peerProperties[p]),
+						}
+						if policyType == parser.PolicyTypeIngress {
+							rel.Source, rel.Target = p, w
+						} else {
+							rel.Source, rel.Target = w, p
+						}
+						relationships = append(relationships, rel)
+					}
+				}
+			}
+		}
+	}
+
+	defaultDeny := make(map[*parser.Resource][]string)
+	for w, directions := range isolated {
+		for _, direction := range []string{"ingress", "egress"} {
+			if directions[direction] && !allowed[w][direction] {
+				defaultDeny[w] = append(defaultDeny[w], direction)
+			}
+		}
+	}
+
+	return relationships, defaultDeny
+}
+
+// DefaultDeny returns the directions, "ingress" and/or "egress", in which the NetworkPolicies
+// isolate each workload without any rule allowing traffic, so that all its traffic is denied.
+// Workloads with traffic allowed in both directions are left out.
+func DefaultDeny(resources []*parser.Resource) map[*parser.Resource][]string {
+	_, defaultDeny := identifyNetworkPolicies(resources)
+	return defaultDeny
+}
+
+// selectedWorkloads returns the workloads in the namespace of policy whose pod templates the
+// pod selector matches. An empty selector selects every workload in the namespace.
+func selectedWorkloads(policy *parser.Resource, selector parser.LabelSelector, resources []*parser.Resource) []*parser.Resource {
+	var selected []*parser.Resource
+	for _, r := range resources {
+		template, ok := r.PodTemplate()
+		if ok && r.Metadata.Namespace == policy.Metadata.Namespace && selector.Matches(template.Metadata.Labels) {
+			selected = append(selected, r)
+		}
+	}
+	return selected
+}
+
+// peerWorkloads returns the workloads a pod and/or namespace selector peer matches. Without a
+// namespace selector, pods are selected in the namespace of the policy; without a pod selector,
+// every pod of the selected namespaces is.
+func peerWorkloads(policy *parser.Resource, peer parser.NetworkPolicyPeer, resources []*parser.Resource) []*parser.Resource {
+	if peer.PodSelector == nil && peer.NamespaceSelector == nil {
+		return nil
+	}
+	var workloads []*parser.Resource
+	for _, r := range resources {
+		template, ok := r.PodTemplate()
+		if !ok {
+			continue
+		}
+		if peer.NamespaceSelector == nil {
+			if r.Metadata.Namespace != policy.Metadata.Namespace {
+				continue
+			}
+		} else if !namespaceSelected(*peer.NamespaceSelector, r.Metadata.Namespace, resources) {
+			continue
+		}
+		if peer.PodSelector != nil && !peer.PodSelector.Matches(template.Metadata.Labels) {
+			continue
+		}
+		workloads = append(workloads, r)
+	}
+	return workloads
+}
+
+// mark records direction for r in set.
+func mark(set map[*parser.Resource]map[string]bool, r *parser.Resource, direction string) {
+	if set[r] == nil {
+		set[r] = make(map[string]bool)
+	}
+	set[r][direction] = true
+}
+
+// withProperties returns properties merged with extra, or properties itself if there is no extra.
+func withProperties(properties, extra map[string]interface{}) map[string]interface{} {
+	if len(extra) == 0 {
+		return properties
+	}
+	merged := make(map[string]interface{}, len(properties)+len(extra))
+	for k, v := range properties {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
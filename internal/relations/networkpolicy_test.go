@@ -0,0 +1,213 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"reflect"
+	"testing"
+)
+
+func TestIdentifyNetworkPolicies(t *testing.T) {
+	manifest := `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: default-deny
+spec:
+  podSelector: {}
+  policyTypes: [Ingress, Egress]
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: db-access
+spec:
+  podSelector:
+    matchLabels:
+      app: db
+  ingress:
+    - from:
+        - podSelector:
+            matchLabels:
+              app: api
+      ports:
+        - port: 5432
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: api-egress
+spec:
+  podSelector:
+    matchLabels:
+      app: api
+  policyTypes: [Egress]
+  egress:
+    - to:
+        - podSelector:
+            matchLabels:
+              app: db
+    - to:
+        - ipBlock:
+            cidr: 10.0.0.0/8
+            except: [10.1.0.0/16]
+      ports:
+        - protocol: TCP
+          port: 8000
+          endPort: 9000
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    metadata:
+      labels:
+        app: api
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  template:
+    metadata:
+      labels:
+        app: db
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships, defaultDeny := identifyNetworkPolicies(resources)
+
+	got := map[string]map[string]interface{}{}
+	for _, rel := range relationships {
+		if rel.Type == "ALLOWS_TRAFFIC" {
+			got[rel.Source.Kind+"/"+rel.Source.Metadata.Name+" -> "+rel.Target.Kind+"/"+rel.Target.Metadata.Name+" "+rel.Properties["direction"].(string)] = rel.Properties
+		}
+	}
+	expected := map[string]map[string]interface{}{
+		"Deployment/api -> StatefulSet/db ingress": {"direction": "ingress", "ports": []string{"TCP/5432"}, "policy": "db-access"},
+		"Deployment/api -> StatefulSet/db egress":  {"direction": "egress", "ports": []string{}, "policy": "api-egress"},
+		"Deployment/api -> ExternalCIDR/10.0.0.0/8 egress": {
+			"direction": "egress", "ports": []string{"TCP/8000-9000"}, "policy": "api-egress", "except": []string{"10.1.0.0/16"},
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected traffic %v, but got %v", expected, got)
+	}
+
+	isolates := 0
+	for _, rel := range relationships {
+		if rel.Type == "ISOLATES" {
+			isolates++
+		}
+	}
+	if isolates != 6 {
+		t.Errorf("expected 6 ISOLATES relationships, but got %d", isolates)
+	}
+
+	if deny := defaultDeny[resources[3]]; !reflect.DeepEqual(deny, []string{"ingress"}) {
+		t.Errorf("expected Deployment api to deny all ingress, but got %v", deny)
+	}
+	if deny := defaultDeny[resources[4]]; !reflect.DeepEqual(deny, []string{"egress"}) {
+		t.Errorf("expected StatefulSet db to deny all egress, but got %v", deny)
+	}
+	for _, r := range resources {
+		if _, ok := r.Properties["defaultDeny"]; ok {
+			t.Errorf("expected %s not to be modified, but got properties %v", r.Identity(), r.Properties)
+		}
+	}
+}
+
+func TestIdentifyNetworkPoliciesAllowAll(t *testing.T) {
+	manifest := `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-all
+spec:
+  podSelector: {}
+  ingress:
+    - {}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var sources []string
+	relationships, _ := identifyNetworkPolicies(resources)
+	for _, rel := range relationships {
+		if rel.Type == "ALLOWS_TRAFFIC" {
+			sources = append(sources, rel.Source.Kind+"/"+rel.Source.Metadata.Name)
+		}
+	}
+	if expected := []string{"Pod/web", "ExternalCIDR/0.0.0.0/0"}; !reflect.DeepEqual(sources, expected) {
+		t.Errorf("expected traffic from %v, but got %v", expected, sources)
+	}
+}
+
+func TestIdentifyNetworkPoliciesNamespaceSelector(t *testing.T) {
+	manifest := `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: web-ingress
+  namespace: web
+spec:
+  podSelector: {}
+  ingress:
+    - from:
+        - namespaceSelector:
+            matchLabels:
+              kubernetes.io/metadata.name: monitoring
+    - from:
+        - namespaceSelector:
+            matchLabels:
+              team: ops
+          podSelector:
+            matchLabels:
+              app: agent
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  namespace: web
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: prometheus
+  namespace: monitoring
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := map[string]map[string]interface{}{}
+	relationships, _ := identifyNetworkPolicies(resources)
+	for _, rel := range relationships {
+		if rel.Type == "ALLOWS_TRAFFIC" {
+			got[rel.Source.Identity().String()+" -> "+rel.Target.Identity().String()] = rel.Properties
+		}
+	}
+	expected := map[string]map[string]interface{}{
+		"Pod/monitoring/prometheus -> Pod/web/web": {"direction": "ingress", "ports": []string{}, "policy": "web-ingress"},
+		"NetworkPeer/namespaceSelector: team=ops; podSelector: app=agent -> Pod/web/web": {
+			"direction": "ingress", "ports": []string{}, "policy": "web-ingress", "unresolved": true,
+		},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected traffic %v, but got %v", expected, got)
+	}
+}
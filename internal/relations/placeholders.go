@@ -0,0 +1,21 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// placeholders holds one placeholder node per referenced identity missing from the manifest,
+// so that every reference to it shares the node. References to resources missing from the
+// manifest, such as the default ServiceAccount, a StorageClass of the cluster or Users and
+// Groups, point to these nodes, and the relationships are flagged with unresolved: true.
+type placeholders map[parser.Identity]*parser.Resource
+
+// resolve returns the resources with the given identity and true, or a placeholder node and
+// false if there are none.
+func (p placeholders) resolve(resources []*parser.Resource, id parser.Identity) ([]*parser.Resource, bool) {
+	if found := findResources(resources, id); len(found) > 0 {
+		return found, true
+	}
+	if _, ok := p[id]; !ok {
+		p[id] = parser.NewPlaceholder(id)
+	}
+	return []*parser.Resource{p[id]}, false
+}
@@ -0,0 +1,182 @@
+package relations
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"helmgraph/internal/parser"
+)
+
+// identifyPullSecrets links workloads to the image pull Secrets their pods use with PULLS_WITH
+// {via}, where via is "pod" for spec.imagePullSecrets and "serviceAccount" for Secrets inherited
+// from the ServiceAccount the pods run as when the spec lists none, and to the registries of
+// their container images with PULLS_FROM {image, container, container_type}. Registries are
+// ImageRegistry nodes. Each pull Secret AUTHENTICATES_TO the registries of the workloads using
+// it that its Docker config holds credentials for, flagged with hasCredentials: true, or to all of
+// them if its Docker config is not in the manifest.
+func identifyPullSecrets(resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	missing := placeholders{}
+	registries := make(map[string]*parser.Resource)
+	authenticates := make(map[*parser.Resource]map[string]bool)
+
+	registryNode := func(host string) *parser.Resource {
+		if r, ok := registries[host]; ok {
+			return r
+		}
+		r := &parser.Resource{
+			Kind:     "ImageRegistry",
+			Metadata: parser.Metadata{Name: host},
+		}
+		registries[host] = r
+		return r
+	}
+
+	for _, r := range resources {
+		template, ok := r.PodTemplate()
+		if !ok {
+			continue
+		}
+
+		var used []string
+		seen := make(map[string]bool)
+		for _, c := range template.Spec.AllContainers() {
+			if c.Image == "" {
+				continue
+			}
+			host := parser.ImageRegistry(c.Image)
+			relationships = append(relationships, &Relationship{
+				Source: r,
+				Target: registryNode(host),
+				Type:   "PULLS_FROM",
+				Properties: map[string]interface{}{
+					"image":          c.Image,
+					"container":      c.Name,
+					"container_type": c.Type,
+				},
+			})
+			if !seen[host] {
+				seen[host] = true
+				used = append(used, host)
+			}
+		}
+
+		for _, ref := range pullSecretRefs(r, &template.Spec, resources) {
+			secrets, resolved := missing.resolve(resources, r.Reference("", "Secret", ref.name))
+			for _, s := range secrets {
+				properties := map[string]interface{}{"via": ref.via}
+				if !resolved {
+					properties["unresolved"] = true
+				}
+				relationships = append(relationships, &Relationship{
+					Source:     r,
+					Target:     s,
+					Type:       "PULLS_WITH",
+					Properties: properties,
+				})
+
+				if authenticates[s] == nil {
+					authenticates[s] = make(map[string]bool)
+				}
+				credentials, known := s.DockerConfigRegistries()
+				for _, host := range used {
+					if authenticates[s][host] {
+						continue
+					}
+					authenticates[s][host] = true
+					if known && !slices.Contains(credentials, host) {
+						continue
+					}
+					properties := map[string]interface{}{}
+					if known {
+						properties["hasCredentials"] = true
+					}
+					relationships = append(relationships, &Relationship{
+						Source:     s,
+						Target:     registryNode(host),
+						Type:       "AUTHENTICATES_TO",
+						Properties: properties,
+					})
+				}
+			}
+		}
+	}
+
+	return relationships
+}
+
+// pullSecretRef is the name of an image pull Secret and how a pod comes to use it.
+type pullSecretRef struct {
+	name string
+	via  string
+}
+
+// pullSecretRefs returns the image pull Secrets of a pod: those of its spec or, if it lists none,
+// those of its ServiceAccount, if the ServiceAccount is in the manifest. Like the ServiceAccount
+// admission plugin, it never combines the two.
+func pullSecretRefs(workload *parser.Resource, spec *parser.PodSpec, resources []*parser.Resource) []pullSecretRef {
+	var refs []pullSecretRef
+	seen := make(map[string]bool)
+	add := func(name, via string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			refs = append(refs, pullSecretRef{name: name, via: via})
+		}
+	}
+
+	if len(spec.ImagePullSecrets) > 0 {
+		for _, ref := range spec.ImagePullSecrets {
+			add(ref.Name, "pod")
+		}
+		return refs
+	}
+	for _, sa := range findResources(resources, workload.Reference("", "ServiceAccount", spec.ServiceAccount())) {
+		names, _ := sa.ImagePullSecrets()
+		for _, name := range names {
+			add(name, "serviceAccount")
+		}
+	}
+	return refs
+}
+
+// analyzePullSecrets reports registries that workloads with image pull Secrets pull from although
+// none of the Secrets has credentials for them. The images may be public, so these are reported
+// as info.
+func analyzePullSecrets(relationships []*Relationship) []Finding {
+	secrets := make(map[*parser.Resource][]*parser.Resource)
+	authenticated := make(map[[2]*parser.Resource]bool)
+	for _, rel := range relationships {
+		switch rel.Type {
+		case "PULLS_WITH":
+			secrets[rel.Source] = append(secrets[rel.Source], rel.Target)
+		case "AUTHENTICATES_TO":
+			authenticated[[2]*parser.Resource{rel.Source, rel.Target}] = true
+		}
+	}
+
+	var findings []Finding
+	reported := make(map[[2]*parser.Resource]bool)
+	for _, rel := range relationships {
+		if rel.Type != "PULLS_FROM" || len(secrets[rel.Source]) == 0 || reported[[2]*parser.Resource{rel.Source, rel.Target}] {
+			continue
+		}
+		var names []string
+		covered := false
+		for _, s := range secrets[rel.Source] {
+			names = append(names, s.Metadata.Name)
+			covered = covered || authenticated[[2]*parser.Resource{s, rel.Target}]
+		}
+		if covered {
+			continue
+		}
+		reported[[2]*parser.Resource{rel.Source, rel.Target}] = true
+		findings = append(findings, Finding{
+			Resource: rel.Source,
+			Severity: SeverityInfo,
+			Message: fmt.Sprintf("pulls from %s, which none of its image pull Secrets (%s) has credentials for",
+				rel.Target.Metadata.Name, strings.Join(names, ", ")),
+		})
+	}
+	return findings
+}
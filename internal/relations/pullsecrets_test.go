@@ -0,0 +1,123 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyPullSecrets(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      serviceAccountName: web
+      imagePullSecrets:
+        - name: ghcr
+      initContainers:
+        - name: migrate
+          image: ghcr.io/acme/migrate:v1
+      containers:
+        - name: web
+          image: nginx:1.27
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+imagePullSecrets:
+  - name: ghcr
+  - name: hub
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: ghcr
+type: kubernetes.io/dockercfg
+stringData:
+  .dockercfg: '{"ghcr.io": {}}'
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	relationships := identifyPullSecrets(resources)
+	expected := []struct {
+		source, kind, target string
+		properties           map[string]interface{}
+	}{
+		{"web", "PULLS_FROM", "ghcr.io", map[string]interface{}{"container": "migrate", "container_type": "init"}},
+		{"web", "PULLS_FROM", "docker.io", map[string]interface{}{"container": "web", "image": "nginx:1.27"}},
+		{"web", "PULLS_WITH", "ghcr", map[string]interface{}{"via": "pod"}},
+		{"ghcr", "AUTHENTICATES_TO", "ghcr.io", map[string]interface{}{"hasCredentials": true}},
+	}
+	if len(relationships) != len(expected) {
+		t.Fatalf("expected %d relationships, but got %d", len(expected), len(relationships))
+	}
+	for i, e := range expected {
+		rel := relationships[i]
+		if rel.Source.Metadata.Name != e.source || rel.Type != e.kind || rel.Target.Metadata.Name != e.target {
+			t.Errorf("expected %s %s %s, but got %s %s %s", e.source, e.kind, e.target, rel.Source.Identity(), rel.Type, rel.Target.Identity())
+			continue
+		}
+		for k, v := range e.properties {
+			if rel.Properties[k] != v {
+				t.Errorf("expected %s %s %s to have %s %v, but got %v", e.source, e.kind, e.target, k, v, rel.Properties[k])
+			}
+		}
+	}
+	if relationships[0].Target != relationships[3].Target {
+		t.Errorf("expected a single ImageRegistry node per registry")
+	}
+
+	findings := analyzePullSecrets(relationships)
+	if expected := "info: Deployment.apps/default/web pulls from docker.io, which none of its image pull Secrets (ghcr) has credentials for"; len(findings) != 1 || findings[0].String() != expected {
+		t.Errorf("expected finding %q, but got %v", expected, findings)
+	}
+}
+
+func TestIdentifyPullSecretsServiceAccount(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      serviceAccountName: web
+      containers:
+        - name: web
+          image: nginx:1.27
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: web
+imagePullSecrets:
+  - name: hub
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	var pullsWith []*Relationship
+	for _, rel := range identifyPullSecrets(resources) {
+		if rel.Type == "PULLS_WITH" {
+			pullsWith = append(pullsWith, rel)
+		}
+	}
+	if len(pullsWith) != 1 || pullsWith[0].Target.Metadata.Name != "hub" {
+		t.Fatalf("expected Deployment web to inherit Secret hub from its ServiceAccount, but got %v", pullsWith)
+	}
+	if pullsWith[0].Properties["via"] != "serviceAccount" || pullsWith[0].Properties["unresolved"] != true {
+		t.Errorf("unexpected properties %v", pullsWith[0].Properties)
+	}
+}
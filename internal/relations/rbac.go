@@ -0,0 +1,234 @@
+package relations
+
+import (
+	"fmt"
+
+	"helmgraph/internal/parser"
+)
+
+// identifyRBAC links workloads to the ServiceAccount their pods run as with RUNS_AS, RoleBindings
+// and ClusterRoleBindings to their subjects with BINDS and to their role with GRANTS, and
+// aggregated ClusterRoles to the ClusterRoles they aggregate with AGGREGATES. Each rule of a
+// granted role is expanded into CAN {verbs, resourceNames, role, binding} relationships from
+// every subject to ApiResource nodes, one per API group and resource in the scope of the binding:
+// its namespace for a RoleBinding, cluster-wide for a ClusterRoleBinding. Non-resource URL rules
+// are not modelled. The default user-facing ClusterRoles, such as cluster-admin and view, are
+// expanded from defaultClusterRoles when the manifest does not include them, and their GRANTS
+// relationships are flagged builtin: true.
+func identifyRBAC(resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	missing := placeholders{}
+	apiResources := make(map[parser.Identity]*parser.Resource)
+
+	apiResource := func(scope, group, resource string) *parser.Resource {
+		id := parser.Identity{Group: group, Kind: "ApiResource", Namespace: scope, Name: resource}
+		if r, ok := apiResources[id]; ok {
+			return r
+		}
+		r := &parser.Resource{
+			Kind:     "ApiResource",
+			APIGroup: group,
+			Metadata: parser.Metadata{Name: resource, Namespace: scope},
+		}
+		apiResources[id] = r
+		return r
+	}
+
+	reference := func(source *parser.Resource, id parser.Identity, relType string) ([]*Relationship, *parser.Resource) {
+		targets, resolved := missing.resolve(resources, id)
+		var properties map[string]interface{}
+		if !resolved {
+			properties = map[string]interface{}{"unresolved": true}
+		}
+		var rels []*Relationship
+		for _, t := range targets {
+			rels = append(rels, &Relationship{Source: source, Target: t, Type: relType, Properties: properties})
+		}
+		if !resolved {
+			return rels, nil
+		}
+		return rels, targets[0]
+	}
+
+	for _, r := range resources {
+		if template, ok := r.PodTemplate(); ok {
+			rels, _ := reference(r, r.Reference("", "ServiceAccount", template.Spec.ServiceAccount()), "RUNS_AS")
+			relationships = append(relationships, rels...)
+		}
+
+		if role, ok := r.Role(); ok && role.AggregationRule != nil {
+			for _, c := range aggregatedRoles(r, role.AggregationRule, resources) {
+				relationships = append(relationships, &Relationship{Source: r, Target: c, Type: "AGGREGATES"})
+			}
+		}
+
+		binding, ok := r.RoleBinding()
+		if !ok {
+			continue
+		}
+		rels, role := reference(r, r.Reference(parser.RBACGroup, binding.RoleRef.Kind, binding.RoleRef.Name), "GRANTS")
+		relationships = append(relationships, rels...)
+
+		var rules []parser.PolicyRule
+		switch builtin, ok := defaultClusterRoles[binding.RoleRef.Name]; {
+		case role != nil:
+			rules = effectiveRules(role, resources, make(map[*parser.Resource]bool))
+		case ok && binding.RoleRef.Kind == "ClusterRole":
+			rules = builtin
+			for _, rel := range rels {
+				rel.Properties["builtin"] = true
+			}
+		}
+		scope := r.Metadata.Namespace
+		if r.IsClusterScoped() {
+			scope = ""
+		}
+
+		for _, s := range binding.Subjects {
+			rels, _ := reference(r, s.Identity(r), "BINDS")
+			relationships = append(relationships, rels...)
+			for _, rel := range rels {
+				for _, rule := range rules {
+					properties := map[string]interface{}{
+						"verbs":   rule.Verbs,
+						"role":    binding.RoleRef.Name,
+						"binding": r.Metadata.Name,
+					}
+					if len(rule.ResourceNames) > 0 {
+						properties["resourceNames"] = rule.ResourceNames
+					}
+					for _, group := range rule.APIGroups {
+						for _, resource := range rule.Resources {
+							relationships = append(relationships, &Relationship{
+								Source:     rel.Target,
+								Target:     apiResource(scope, group, resource),
+								Type:       "CAN",
+								Properties: properties,
+							})
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return relationships
+}
+
+// aggregatedRoles returns the ClusterRoles, other than role itself, whose labels match any
+// cluster role selector of the aggregation rule.
+func aggregatedRoles(role *parser.Resource, rule *parser.AggregationRule, resources []*parser.Resource) []*parser.Resource {
+	var aggregated []*parser.Resource
+	for _, c := range resources {
+		if c == role || c.Group() != parser.RBACGroup || c.Kind != "ClusterRole" {
+			continue
+		}
+		for _, selector := range rule.ClusterRoleSelectors {
+			if selector.Matches(c.Metadata.Labels) {
+				aggregated = append(aggregated, c)
+				break
+			}
+		}
+	}
+	return aggregated
+}
+
+// effectiveRules returns the rules a role grants. As the aggregation controller does, the rules
+// of an aggregated ClusterRole are those of the ClusterRoles it aggregates, recursively.
+func effectiveRules(role *parser.Resource, resources []*parser.Resource, visited map[*parser.Resource]bool) []parser.PolicyRule {
+	if visited[role] {
+		return nil
+	}
+	visited[role] = true
+
+	spec, ok := role.Role()
+	if !ok {
+		return nil
+	}
+	if spec.AggregationRule == nil {
+		return spec.Rules
+	}
+	var rules []parser.PolicyRule
+	for _, c := range aggregatedRoles(role, spec.AggregationRule, resources) {
+		rules = append(rules, effectiveRules(c, resources, visited)...)
+	}
+	return rules
+}
+
+// sensitivePermissions are the permissions whose holders are reported when auditing workloads.
+var sensitivePermissions = []struct {
+	resource    string
+	verbs       []string
+	description string
+}{
+	{"secrets", []string{"get", "list", "watch"}, "read Secrets"},
+	{"pods", []string{"create"}, "create Pods"},
+}
+
+// analyzeRBAC reports workloads whose ServiceAccount can read Secrets or create Pods, and those
+// whose ServiceAccount is bound to a role that is neither in the manifest nor a default
+// ClusterRole, whose permissions cannot be audited.
+func analyzeRBAC(relationships []*Relationship) []Finding {
+	can := make(map[*parser.Resource][]*Relationship)
+	unknownRoles := make(map[*parser.Resource]*parser.Resource)
+	bindings := make(map[*parser.Resource][]*parser.Resource)
+	for _, rel := range relationships {
+		switch rel.Type {
+		case "CAN":
+			can[rel.Source] = append(can[rel.Source], rel)
+		case "GRANTS":
+			if rel.Properties["unresolved"] == true && rel.Properties["builtin"] != true {
+				unknownRoles[rel.Source] = rel.Target
+			}
+		case "BINDS":
+			bindings[rel.Target] = append(bindings[rel.Target], rel.Source)
+		}
+	}
+
+	var findings []Finding
+	for _, rel := range relationships {
+		if rel.Type != "RUNS_AS" {
+			continue
+		}
+		for _, p := range sensitivePermissions {
+			for _, permission := range can[rel.Target] {
+				if grantsPermission(permission, p.resource, p.verbs) {
+					findings = append(findings, Finding{
+						Resource: rel.Source,
+						Severity: SeverityWarning,
+						Message: fmt.Sprintf("can %s as ServiceAccount %s (role %s, binding %s)",
+							p.description, rel.Target.Metadata.Name, permission.Properties["role"], permission.Properties["binding"]),
+					})
+					break
+				}
+			}
+		}
+		for _, binding := range bindings[rel.Target] {
+			if role, ok := unknownRoles[binding]; ok {
+				findings = append(findings, Finding{
+					Resource: rel.Source,
+					Severity: SeverityWarning,
+					Message: fmt.Sprintf("runs as ServiceAccount %s, bound by %s to %s %s which is not in the manifest; its permissions are unknown",
+						rel.Target.Metadata.Name, binding.Metadata.Name, role.Kind, role.Metadata.Name),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+// grantsPermission reports whether a CAN relationship grants any of verbs on a core resource.
+func grantsPermission(permission *Relationship, resource string, verbs []string) bool {
+	target := permission.Target
+	if (target.Group() != "" && target.Group() != "*") || (target.Metadata.Name != resource && target.Metadata.Name != "*") {
+		return false
+	}
+	for _, granted := range permission.Properties["verbs"].([]string) {
+		for _, v := range verbs {
+			if granted == v || granted == "*" {
+				return true
+			}
+		}
+	}
+	return false
+}
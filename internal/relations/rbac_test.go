@@ -0,0 +1,243 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"reflect"
+	"testing"
+)
+
+func TestIdentifyRBAC(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: operator
+  namespace: ops
+spec:
+  template:
+    spec:
+      serviceAccountName: operator
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: operator
+  namespace: ops
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: secret-reader
+  namespace: ops
+rules:
+  - apiGroups: [""]
+    resources: [secrets]
+    resourceNames: [operator-token]
+    verbs: [get]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: operator-secrets
+  namespace: ops
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: secret-reader
+subjects:
+  - kind: ServiceAccount
+    name: operator
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: operator-aggregate
+aggregationRule:
+  clusterRoleSelectors:
+    - matchLabels:
+        aggregate-to-operator: "true"
+rules: []
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: pod-manager
+  labels:
+    aggregate-to-operator: "true"
+rules:
+  - apiGroups: ["", apps]
+    resources: [pods, deployments]
+    verbs: [create, delete]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: operator-pods
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: operator-aggregate
+subjects:
+  - kind: ServiceAccount
+    name: operator
+    namespace: ops
+  - kind: User
+    apiGroup: rbac.authorization.k8s.io
+    name: alice
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	relationships := identifyRBAC(resources)
+
+	got := map[string]map[string]interface{}{}
+	for _, rel := range relationships {
+		got[rel.Source.Identity().String()+" "+rel.Type+" "+rel.Target.Identity().String()] = rel.Properties
+	}
+	createDelete := map[string]interface{}{"verbs": []string{"create", "delete"}, "role": "operator-aggregate", "binding": "operator-pods"}
+	expected := map[string]map[string]interface{}{
+		"Deployment.apps/ops/operator RUNS_AS ServiceAccount/ops/operator":                                                           nil,
+		"RoleBinding.rbac.authorization.k8s.io/ops/operator-secrets GRANTS Role.rbac.authorization.k8s.io/ops/secret-reader":         nil,
+		"RoleBinding.rbac.authorization.k8s.io/ops/operator-secrets BINDS ServiceAccount/ops/operator":                               nil,
+		"ClusterRole.rbac.authorization.k8s.io/operator-aggregate AGGREGATES ClusterRole.rbac.authorization.k8s.io/pod-manager":      nil,
+		"ClusterRoleBinding.rbac.authorization.k8s.io/operator-pods GRANTS ClusterRole.rbac.authorization.k8s.io/operator-aggregate": nil,
+		"ClusterRoleBinding.rbac.authorization.k8s.io/operator-pods BINDS ServiceAccount/ops/operator":                               nil,
+		"ClusterRoleBinding.rbac.authorization.k8s.io/operator-pods BINDS User.rbac.authorization.k8s.io/alice":                      {"unresolved": true},
+		"ServiceAccount/ops/operator CAN ApiResource/ops/secrets": {
+			"verbs": []string{"get"}, "resourceNames": []string{"operator-token"}, "role": "secret-reader", "binding": "operator-secrets",
+		},
+		"ServiceAccount/ops/operator CAN ApiResource/pods":                      createDelete,
+		"ServiceAccount/ops/operator CAN ApiResource/deployments":               createDelete,
+		"ServiceAccount/ops/operator CAN ApiResource.apps/pods":                 createDelete,
+		"ServiceAccount/ops/operator CAN ApiResource.apps/deployments":          createDelete,
+		"User.rbac.authorization.k8s.io/alice CAN ApiResource/pods":             createDelete,
+		"User.rbac.authorization.k8s.io/alice CAN ApiResource/deployments":      createDelete,
+		"User.rbac.authorization.k8s.io/alice CAN ApiResource.apps/pods":        createDelete,
+		"User.rbac.authorization.k8s.io/alice CAN ApiResource.apps/deployments": createDelete,
+	}
+	for k, v := range expected {
+		properties, ok := got[k]
+		if !ok {
+			t.Errorf("missing relationship %s", k)
+			continue
+		}
+		if len(v) > 0 && !reflect.DeepEqual(properties, v) {
+			t.Errorf("expected %s properties %v, but got %v", k, v, properties)
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected %d relationships, but got %d", len(expected), len(got))
+	}
+
+	findings := analyzeRBAC(relationships)
+	expectedFindings := []string{
+		"warning: Deployment.apps/ops/operator can read Secrets as ServiceAccount operator (role secret-reader, binding operator-secrets)",
+		"warning: Deployment.apps/ops/operator can create Pods as ServiceAccount operator (role operator-aggregate, binding operator-pods)",
+	}
+	if len(findings) != len(expectedFindings) {
+		t.Fatalf("expected %d findings, but got %v", len(expectedFindings), findings)
+	}
+	for i, e := range expectedFindings {
+		if findings[i].String() != e {
+			t.Errorf("expected finding %q, but got %q", e, findings[i].String())
+		}
+	}
+}
+
+func TestAnalyzeRBACUnresolvedRoles(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: admin
+spec:
+  template:
+    spec:
+      serviceAccountName: admin
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: viewer
+spec:
+  template:
+    spec:
+      serviceAccountName: viewer
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: custom
+spec:
+  template:
+    spec:
+      serviceAccountName: custom
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: admin
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+  - kind: ServiceAccount
+    name: admin
+    namespace: default
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: viewer
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+  - kind: ServiceAccount
+    name: viewer
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: custom
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: external-role
+subjects:
+  - kind: ServiceAccount
+    name: custom
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	relationships := identifyRBAC(resources)
+	for _, rel := range relationships {
+		if rel.Type == "GRANTS" && rel.Source.Metadata.Name != "custom" && rel.Properties["builtin"] != true {
+			t.Errorf("expected %s to grant a builtin ClusterRole, but got %v", rel.Source.Identity(), rel.Properties)
+		}
+	}
+
+	findings := analyzeRBAC(relationships)
+	expected := []string{
+		"warning: Deployment.apps/default/admin can read Secrets as ServiceAccount admin (role cluster-admin, binding admin)",
+		"warning: Deployment.apps/default/admin can create Pods as ServiceAccount admin (role cluster-admin, binding admin)",
+		"warning: Deployment.apps/default/custom runs as ServiceAccount custom, bound by custom to ClusterRole external-role which is not in the manifest; its permissions are unknown",
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, but got %v", len(expected), findings)
+	}
+	for i, e := range expected {
+		if findings[i].String() != e {
+			t.Errorf("expected finding %q, but got %q", e, findings[i].String())
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// identifyServiceSelectors links a Service to the workloads in its namespace whose pods it selects. As in
+// Kubernetes, the selector is matched against the pod template labels, not the labels of the
+// workload object itself. The relationship records the pod labels that matched and flags
+// workloads whose own labels disagree with their pods' on a selected key.
+func identifyServiceSelectors(service *parser.Resource, resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	for _, w := range resources {
+		template, ok := w.PodTemplate()
+		if !ok || w.Metadata.Namespace != service.Metadata.Namespace || !selectorsMatch(service.Spec.Selector, template.Metadata.Labels) {
+			continue
+		}
+
+		matched := map[string]string{}
+		disagree := false
+		for k := range service.Spec.Selector.MatchLabels {
+			matched[k] = template.Metadata.Labels[k]
+			if w.Metadata.Labels[k] != template.Metadata.Labels[k] {
+				disagree = true
+			}
+		}
+
+		relationships = append(relationships, &Relationship{
+			Source: service,
+			Target: w,
+			Type:   "SELECTS",
+			Properties: map[string]interface{}{
+				"selector_labels": service.Spec.Selector.MatchLabels,
+				"matched_labels":  matched,
+				"labels_disagree": disagree,
+			},
+		})
+	}
+	return relationships
+}
+
+// selectorsMatch reports whether a Service selector matches the labels. Unlike other label
+// selectors, an empty Service selector matches nothing: such a Service has its endpoints
+// managed outside of Kubernetes.
+func selectorsMatch(serviceSelector parser.LabelSelector, labels map[string]string) bool {
+	if serviceSelector.Empty() {
+		return false
+	}
+	return serviceSelector.Matches(labels)
+}
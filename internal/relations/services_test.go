@@ -0,0 +1,75 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyServiceSelectors(t *testing.T) {
+	manifest := `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web-deployment
+spec:
+  template:
+    metadata:
+      labels:
+        app: web
+        version: v1
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: impostor
+  labels:
+    app: web
+spec:
+  template:
+    metadata:
+      labels:
+        app: impostor
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+  labels:
+    app: web
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := identifyServiceSelectors(resources[0], resources)
+	if len(relationships) != 2 {
+		t.Fatalf("expected 2 relationships, but got %d", len(relationships))
+	}
+
+	deployment := relationships[0]
+	if deployment.Target.Metadata.Name != "web" {
+		t.Fatalf("expected the Service to select Deployment web, but got %s", deployment.Target.Metadata.Name)
+	}
+	if matched := deployment.Properties["matched_labels"].(map[string]string); len(matched) != 1 || matched["app"] != "web" {
+		t.Errorf("expected matched labels app=web, but got %v", matched)
+	}
+	if deployment.Properties["labels_disagree"] != true {
+		t.Error("expected the Deployment labels to be flagged as disagreeing with its pod labels")
+	}
+
+	pod := relationships[1]
+	if pod.Target.Metadata.Name != "debug" || pod.Properties["labels_disagree"] != false {
+		t.Errorf("expected the Service to select Pod debug with agreeing labels, but got %s %v", pod.Target.Metadata.Name, pod.Properties)
+	}
+}
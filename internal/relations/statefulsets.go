@@ -0,0 +1,131 @@
+package relations
+
+import (
+	"fmt"
+
+	"helmgraph/internal/parser"
+)
+
+// identifyStatefulSets models the volume claim templates of every StatefulSet as
+// VolumeClaimTemplate nodes, named like the claims created from them ("<template>-<statefulset>"),
+// linked with HAS_CLAIM_TEMPLATE {mountPaths} and to their StorageClass as claims are. With
+// opts.ExpandClaims, the claim of every replica, "<template>-<statefulset>-<ordinal>", is added
+// as a PersistentVolumeClaim node that the template INSTANTIATES and the StatefulSet USES_PVC;
+// claims not in the manifest are flagged generated.
+// A StatefulSet is also linked to its governing Service with GOVERNED_BY {headless}.
+func identifyStatefulSets(resources []*parser.Resource, opts Options) []*Relationship {
+	var relationships []*Relationship
+	missing := placeholders{}
+
+	for _, r := range resources {
+		if r.Kind != "StatefulSet" {
+			continue
+		}
+
+		for _, t := range r.Spec.VolumeClaimTemplates {
+			template := volumeClaimTemplate(r, t)
+			relationships = append(relationships, &Relationship{
+				Source: r,
+				Target: template,
+				Type:   "HAS_CLAIM_TEMPLATE",
+				Properties: map[string]interface{}{
+					"mountPaths": templateMountPaths(&r.Spec.Template.Spec, t.Metadata.Name),
+				},
+			})
+			relationships = append(relationships, identifyStorageClass(template, &t.Spec, resources, missing)...)
+			if !opts.ExpandClaims {
+				continue
+			}
+			for ordinal := 0; ordinal < r.Replicas(); ordinal++ {
+				for _, pvc := range replicaClaims(r, template, ordinal, resources) {
+					relationships = append(relationships, &Relationship{
+						Source:     template,
+						Target:     pvc,
+						Type:       "INSTANTIATES",
+						Properties: map[string]interface{}{"ordinal": ordinal},
+					}, &Relationship{
+						Source:     r,
+						Target:     pvc,
+						Type:       "USES_PVC",
+						Properties: map[string]interface{}{"ordinal": ordinal, "volume": t.Metadata.Name},
+					})
+				}
+			}
+		}
+
+		if r.Spec.ServiceName != "" {
+			services, resolved := missing.resolve(resources, r.Reference("", "Service", r.Spec.ServiceName))
+			for _, s := range services {
+				properties := map[string]interface{}{}
+				if resolved {
+					clusterIP, _ := s.StringField("spec", "clusterIP")
+					properties["headless"] = clusterIP == "None"
+				} else {
+					properties["unresolved"] = true
+				}
+				relationships = append(relationships, &Relationship{
+					Source:     r,
+					Target:     s,
+					Type:       "GOVERNED_BY",
+					Properties: properties,
+				})
+			}
+		}
+	}
+
+	return relationships
+}
+
+// volumeClaimTemplate returns the VolumeClaimTemplate node of a claim template of statefulSet,
+// with the storage class, requested size and access modes of the claims created from it. A
+// template without a storage class uses the cluster's default StorageClass.
+func volumeClaimTemplate(statefulSet *parser.Resource, claim parser.PersistentVolumeClaim) *parser.Resource {
+	properties := map[string]interface{}{
+		"template":    claim.Metadata.Name,
+		"accessModes": claim.Spec.AccessModes,
+	}
+	if claim.Spec.StorageClassName != nil {
+		properties["storageClassName"] = *claim.Spec.StorageClassName
+	}
+	if storage, ok := claim.Spec.Resources.Requests["storage"]; ok {
+		properties["storage"] = storage
+	}
+	return &parser.Resource{
+		Kind: "VolumeClaimTemplate",
+		Metadata: parser.Metadata{
+			Name:      fmt.Sprintf("%s-%s", claim.Metadata.Name, statefulSet.Metadata.Name),
+			Namespace: statefulSet.Metadata.Namespace,
+		},
+		Properties: properties,
+	}
+}
+
+// replicaClaims returns the PersistentVolumeClaim of a replica created from a claim template:
+// the claim in the manifest if there is one, otherwise a generated node.
+func replicaClaims(statefulSet, template *parser.Resource, ordinal int, resources []*parser.Resource) []*parser.Resource {
+	name := fmt.Sprintf("%s-%d", template.Metadata.Name, ordinal)
+	if found := findResources(resources, statefulSet.Reference("", "PersistentVolumeClaim", name)); len(found) > 0 {
+		return found
+	}
+	return []*parser.Resource{{
+		APIVersion: "v1",
+		Kind:       "PersistentVolumeClaim",
+		Metadata: parser.Metadata{
+			Name:      name,
+			Namespace: statefulSet.Metadata.Namespace,
+		},
+		Properties: map[string]interface{}{
+			"generated": true,
+		},
+	}}
+}
+
+// templateMountPaths returns the paths at which the containers of a pod mount the named volume.
+func templateMountPaths(spec *parser.PodSpec, volume string) []string {
+	paths := []string{}
+	for _, c := range spec.MountingContainers(volume) {
+		containerPaths, _ := c.MountPaths(volume)
+		paths = append(paths, containerPaths...)
+	}
+	return paths
+}
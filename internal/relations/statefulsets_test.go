@@ -0,0 +1,133 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyStatefulSets(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 2
+  serviceName: db-headless
+  template:
+    spec:
+      containers:
+        - name: postgres
+          volumeMounts:
+            - name: data
+              mountPath: /var/lib/postgresql
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+      spec:
+        storageClassName: fast
+        accessModes: [ReadWriteOnce]
+        resources:
+          requests:
+            storage: 10Gi
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: db-headless
+spec:
+  clusterIP: None
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-db-0
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: cache
+spec:
+  serviceName: cache
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	relationships := identifyStatefulSets(resources, Options{})
+	if len(relationships) != 4 {
+		t.Fatalf("expected 4 relationships, but got %d", len(relationships))
+	}
+
+	claim := relationships[0]
+	if claim.Type != "HAS_CLAIM_TEMPLATE" || claim.Target.Kind != "VolumeClaimTemplate" || claim.Target.Metadata.Name != "data-db" {
+		t.Fatalf("expected StatefulSet db to have claim template data-db, but got %s %s", claim.Type, claim.Target.Identity())
+	}
+	if paths, _ := claim.Properties["mountPaths"].([]string); len(paths) != 1 || paths[0] != "/var/lib/postgresql" {
+		t.Errorf("unexpected mount paths %v", claim.Properties["mountPaths"])
+	}
+	properties := claim.Target.Properties
+	if properties["storageClassName"] != "fast" || properties["storage"] != "10Gi" {
+		t.Errorf("unexpected claim template properties %v", properties)
+	}
+	if modes, _ := properties["accessModes"].([]string); len(modes) != 1 || modes[0] != "ReadWriteOnce" {
+		t.Errorf("unexpected access modes %v", properties["accessModes"])
+	}
+
+	class := relationships[1]
+	if class.Type != "USES_STORAGE_CLASS" || class.Source != claim.Target || class.Target.Metadata.Name != "fast" || class.Properties["unresolved"] != true {
+		t.Errorf("expected claim template data-db to use the unresolved StorageClass fast, but got %s %s %v", class.Type, class.Target.Identity(), class.Properties)
+	}
+
+	governed := relationships[2]
+	if governed.Type != "GOVERNED_BY" || governed.Target != resources[1] || governed.Properties["headless"] != true {
+		t.Errorf("expected StatefulSet db to be governed by headless Service db-headless, but got %s %s %v", governed.Type, governed.Target.Identity(), governed.Properties)
+	}
+	missing := relationships[3]
+	if missing.Target.Properties["placeholder"] != true || missing.Properties["unresolved"] != true {
+		t.Errorf("expected Service cache to be an unresolved placeholder, but got %v", missing.Properties)
+	}
+}
+
+func TestIdentifyStatefulSetsExpandClaims(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 2
+  volumeClaimTemplates:
+    - metadata:
+        name: data
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-db-0
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	claims := map[int]*parser.Resource{}
+	for _, rel := range identifyStatefulSets(resources, Options{ExpandClaims: true}) {
+		if rel.Type != "USES_PVC" {
+			continue
+		}
+		claims[rel.Properties["ordinal"].(int)] = rel.Target
+	}
+	if len(claims) != 2 {
+		t.Fatalf("expected claims for 2 replicas, but got %v", claims)
+	}
+	if claims[0] != resources[1] {
+		t.Errorf("expected replica 0 to use PersistentVolumeClaim data-db-0 from the manifest, but got %s", claims[0].Identity())
+	}
+	if claims[1].Metadata.Name != "data-db-1" || claims[1].Properties["generated"] != true {
+		t.Errorf("expected replica 1 to use generated PersistentVolumeClaim data-db-1, but got %s %v", claims[1].Identity(), claims[1].Properties)
+	}
+}
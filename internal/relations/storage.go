@@ -0,0 +1,69 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// identifyStorage links every PersistentVolumeClaim to the StorageClass it provisions from and
+// to the PersistentVolume it is bound to through spec.volumeName.
+func identifyStorage(resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+	missing := placeholders{}
+
+	for _, r := range resources {
+		spec, ok := r.PersistentVolumeClaimSpec()
+		if !ok {
+			continue
+		}
+		relationships = append(relationships, identifyStorageClass(r, spec, resources, missing)...)
+		if spec.VolumeName != "" {
+			for _, pv := range findResources(resources, r.Reference("", "PersistentVolume", spec.VolumeName)) {
+				relationships = append(relationships, &Relationship{
+					Source: r,
+					Target: pv,
+					Type:   "BOUND_TO",
+				})
+			}
+		}
+	}
+
+	return relationships
+}
+
+// identifyStorageClass links a claim, or a claim template, to its StorageClass with
+// USES_STORAGE_CLASS. A claim without storageClassName uses the StorageClass annotated as the
+// cluster's default, if the manifest has one, and the relationship is flagged default: true; a
+// claim with an empty storageClassName binds to volumes without a class and uses none.
+func identifyStorageClass(claim *parser.Resource, spec *parser.PersistentVolumeClaimSpec, resources []*parser.Resource, missing placeholders) []*Relationship {
+	var relationships []*Relationship
+
+	if spec.StorageClassName == nil {
+		for _, sc := range resources {
+			if sc.IsDefaultStorageClass() {
+				relationships = append(relationships, &Relationship{
+					Source:     claim,
+					Target:     sc,
+					Type:       "USES_STORAGE_CLASS",
+					Properties: map[string]interface{}{"default": true},
+				})
+			}
+		}
+		return relationships
+	}
+	if *spec.StorageClassName == "" {
+		return nil
+	}
+
+	classes, resolved := missing.resolve(resources, claim.Reference("storage.k8s.io", "StorageClass", *spec.StorageClassName))
+	for _, sc := range classes {
+		properties := map[string]interface{}{"default": false}
+		if !resolved {
+			properties["unresolved"] = true
+		}
+		relationships = append(relationships, &Relationship{
+			Source:     claim,
+			Target:     sc,
+			Type:       "USES_STORAGE_CLASS",
+			Properties: properties,
+		})
+	}
+	return relationships
+}
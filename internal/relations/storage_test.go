@@ -0,0 +1,114 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyStorage(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+        - name: web
+          volumeMounts:
+            - name: uploads
+              mountPath: /srv/uploads
+              readOnly: true
+        - name: sync
+          volumeMounts:
+            - name: uploads
+              mountPath: /data
+      volumes:
+        - name: uploads
+          persistentVolumeClaim:
+            claimName: uploads
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: uploads
+spec:
+  volumeName: uploads-pv
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: archive
+spec:
+  storageClassName: cold
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: static
+spec:
+  storageClassName: ""
+---
+apiVersion: v1
+kind: PersistentVolume
+metadata:
+  name: uploads-pv
+---
+apiVersion: storage.k8s.io/v1
+kind: StorageClass
+metadata:
+  name: standard
+  annotations:
+    storageclass.kubernetes.io/is-default-class: "true"
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parser.ResolveNamespaces(resources, "")
+
+	template, _ := resources[0].PodTemplate()
+	uses := identifyPodReferences(resources[0], &template.Spec, resources)
+	if len(uses) != 2 {
+		t.Fatalf("expected a USES_PVC relationship per container, but got %d", len(uses))
+	}
+	for i, e := range []struct {
+		container string
+		path      string
+		readOnly  bool
+	}{{"web", "/srv/uploads", true}, {"sync", "/data", false}} {
+		rel := uses[i]
+		if rel.Type != "USES_PVC" || rel.Target != resources[1] || rel.Properties["container"] != e.container {
+			t.Fatalf("expected container %s to use PersistentVolumeClaim uploads, but got %s %s %v", e.container, rel.Type, rel.Target.Identity(), rel.Properties)
+		}
+		if paths, _ := rel.Properties["mountPaths"].([]string); len(paths) != 1 || paths[0] != e.path {
+			t.Errorf("expected container %s to mount at %s, but got %v", e.container, e.path, rel.Properties["mountPaths"])
+		}
+		if rel.Properties["readOnly"] != e.readOnly {
+			t.Errorf("expected readOnly %v for container %s, but got %v", e.readOnly, e.container, rel.Properties["readOnly"])
+		}
+	}
+
+	relationships := identifyStorage(resources)
+	expected := []string{
+		"PersistentVolumeClaim/default/uploads USES_STORAGE_CLASS StorageClass.storage.k8s.io/standard",
+		"PersistentVolumeClaim/default/uploads BOUND_TO PersistentVolume/uploads-pv",
+		"PersistentVolumeClaim/default/archive USES_STORAGE_CLASS StorageClass.storage.k8s.io/cold",
+	}
+	if len(relationships) != len(expected) {
+		t.Fatalf("expected %d relationships, but got %d", len(expected), len(relationships))
+	}
+	for i, e := range expected {
+		rel := relationships[i]
+		if got := rel.Source.Identity().String() + " " + rel.Type + " " + rel.Target.Identity().String(); got != e {
+			t.Errorf("expected %q, but got %q", e, got)
+		}
+	}
+	if relationships[0].Properties["default"] != true {
+		t.Errorf("expected PersistentVolumeClaim uploads to use the default StorageClass, but got %v", relationships[0].Properties)
+	}
+	if cold := relationships[2]; cold.Target.Properties["placeholder"] != true || cold.Properties["unresolved"] != true {
+		t.Errorf("expected StorageClass cold to be an unresolved placeholder, but got %v", cold.Properties)
+	}
+}
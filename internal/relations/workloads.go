@@ -0,0 +1,162 @@
+package relations
+
+import "helmgraph/internal/parser"
+
+// identifyPodReferences identifies the ConfigMaps and Secrets the pods of workload use through
+// volumes, envFrom and env valueFrom references of any container, and the PersistentVolumeClaims
+// they mount. Each relationship is tagged with the container that uses the reference and its
+// container type; a volume mounted by several containers gives one relationship per container.
+func identifyPodReferences(workload *parser.Resource, spec *parser.PodSpec, resources []*parser.Resource) []*Relationship {
+	var relationships []*Relationship
+
+	for _, v := range spec.Volumes {
+		if v.ConfigMap.Name != "" {
+			for _, c := range findResources(resources, workload.Reference("", "ConfigMap", v.ConfigMap.Name)) {
+				for _, properties := range volumeProperties(spec, v.Name) {
+					relationships = append(relationships, &Relationship{
+						Source:     workload,
+						Target:     c,
+						Type:       "USES_CONFIG",
+						Properties: properties,
+					})
+				}
+			}
+		}
+		if v.Secret.SecretName != "" {
+			for _, s := range findResources(resources, workload.Reference("", "Secret", v.Secret.SecretName)) {
+				for _, properties := range volumeProperties(spec, v.Name) {
+					relationships = append(relationships, &Relationship{
+						Source:     workload,
+						Target:     s,
+						Type:       "USES_SECRET",
+						Properties: properties,
+					})
+				}
+			}
+		}
+		if v.PersistentVolumeClaim.ClaimName != "" {
+			for _, p := range findResources(resources, workload.Reference("", "PersistentVolumeClaim", v.PersistentVolumeClaim.ClaimName)) {
+				for _, properties := range claimProperties(spec, v) {
+					relationships = append(relationships, &Relationship{
+						Source:     workload,
+						Target:     p,
+						Type:       "USES_PVC",
+						Properties: properties,
+					})
+				}
+			}
+		}
+	}
+
+	for _, c := range spec.AllContainers() {
+		for _, e := range c.EnvFrom {
+			if e.ConfigMapRef.Name != "" {
+				for _, cm := range findResources(resources, workload.Reference("", "ConfigMap", e.ConfigMapRef.Name)) {
+					relationships = append(relationships, &Relationship{
+						Source: workload,
+						Target: cm,
+						Type:   "USES_CONFIG",
+						Properties: map[string]interface{}{
+							"envFrom":        true,
+							"container":      c.Name,
+							"container_type": c.Type,
+						},
+					})
+				}
+			}
+			if e.SecretRef.Name != "" {
+				for _, s := range findResources(resources, workload.Reference("", "Secret", e.SecretRef.Name)) {
+					relationships = append(relationships, &Relationship{
+						Source: workload,
+						Target: s,
+						Type:   "USES_SECRET",
+						Properties: map[string]interface{}{
+							"envFrom":        true,
+							"container":      c.Name,
+							"container_type": c.Type,
+						},
+					})
+				}
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom.ConfigMapKeyRef.Name != "" {
+				for _, cm := range findResources(resources, workload.Reference("", "ConfigMap", e.ValueFrom.ConfigMapKeyRef.Name)) {
+					relationships = append(relationships, &Relationship{
+						Source: workload,
+						Target: cm,
+						Type:   "USES_CONFIG",
+						Properties: map[string]interface{}{
+							"env_var_name":   e.Name,
+							"container":      c.Name,
+							"container_type": c.Type,
+						},
+					})
+				}
+			}
+			if e.ValueFrom.SecretKeyRef.Name != "" {
+				for _, s := range findResources(resources, workload.Reference("", "Secret", e.ValueFrom.SecretKeyRef.Name)) {
+					relationships = append(relationships, &Relationship{
+						Source: workload,
+						Target: s,
+						Type:   "USES_SECRET",
+						Properties: map[string]interface{}{
+							"env_var_name":   e.Name,
+							"container":      c.Name,
+							"container_type": c.Type,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	return relationships
+}
+
+// volumeProperties returns the relationship properties for a volume reference, one set per
+// container mounting the volume, or a single set without container if no container mounts it.
+func volumeProperties(spec *parser.PodSpec, volume string) []map[string]interface{} {
+	containers := spec.MountingContainers(volume)
+	if len(containers) == 0 {
+		return []map[string]interface{}{{"volume": volume}}
+	}
+
+	properties := make([]map[string]interface{}, 0, len(containers))
+	for _, c := range containers {
+		properties = append(properties, map[string]interface{}{
+			"volume":         volume,
+			"container":      c.Name,
+			"container_type": c.Type,
+		})
+	}
+	return properties
+}
+
+// claimProperties returns the relationship properties for a PersistentVolumeClaim volume, as
+// volumeProperties does, with the paths each container mounts the claim at and whether it can
+// only read from it, because either the volume or all of its mounts are read-only.
+func claimProperties(spec *parser.PodSpec, volume parser.Volume) []map[string]interface{} {
+	readOnly := volume.PersistentVolumeClaim.ReadOnly
+	containers := spec.MountingContainers(volume.Name)
+	if len(containers) == 0 {
+		return []map[string]interface{}{{
+			"volume":     volume.Name,
+			"mountPaths": []string{},
+			"readOnly":   readOnly,
+		}}
+	}
+
+	properties := make([]map[string]interface{}, 0, len(containers))
+	for _, c := range containers {
+		paths, mountsReadOnly := c.MountPaths(volume.Name)
+		properties = append(properties, map[string]interface{}{
+			"volume":         volume.Name,
+			"container":      c.Name,
+			"container_type": c.Type,
+			"mountPaths":     paths,
+			"readOnly":       readOnly || mountsReadOnly,
+		})
+	}
+	return properties
+}
@@ -0,0 +1,193 @@
+package relations
+
+import (
+	"helmgraph/internal/parser"
+	"testing"
+)
+
+func TestIdentifyWorkloadKinds(t *testing.T) {
+	manifest := `
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+            - name: backup
+              envFrom:
+                - secretRef:
+                    name: db-credentials
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: agent
+spec:
+  template:
+    metadata:
+      labels:
+        app: agent
+    spec:
+      volumes:
+        - name: config
+          configMap:
+            name: agent-config
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+spec:
+  template:
+    spec:
+      containers:
+        - name: migrate
+          env:
+            - name: PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: db-credentials
+                  key: password
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: debug
+spec:
+  containers:
+    - name: debug
+      envFrom:
+        - configMapRef:
+            name: agent-config
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: agent
+spec:
+  selector:
+    app: agent
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-credentials
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: agent-config
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := Identify(resources)
+
+	expected := map[string]bool{
+		"CronJob/backup USES_SECRET Secret/db-credentials":   true,
+		"DaemonSet/agent USES_CONFIG ConfigMap/agent-config": true,
+		"Job/migrate USES_SECRET Secret/db-credentials":      true,
+		"Pod/debug USES_CONFIG ConfigMap/agent-config":       true,
+		"Service/agent SELECTS DaemonSet/agent":              true,
+		"CronJob/backup RUNS_AS ServiceAccount/default":      true,
+		"DaemonSet/agent RUNS_AS ServiceAccount/default":     true,
+		"Job/migrate RUNS_AS ServiceAccount/default":         true,
+		"Pod/debug RUNS_AS ServiceAccount/default":           true,
+	}
+	got := map[string]bool{}
+	for _, rel := range relationships {
+		got[rel.Source.Kind+"/"+rel.Source.Metadata.Name+" "+rel.Type+" "+rel.Target.Kind+"/"+rel.Target.Metadata.Name] = true
+	}
+	for e := range expected {
+		if !got[e] {
+			t.Errorf("missing relationship %s", e)
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected %d relationships, but got %v", len(expected), got)
+	}
+}
+
+func TestIdentifyPodReferencesContainers(t *testing.T) {
+	manifest := `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+        - name: wait-for-db
+          envFrom:
+            - secretRef:
+                name: db-credentials
+      containers:
+        - name: app
+          volumeMounts:
+            - name: config
+              mountPath: /etc/app
+        - name: sidecar
+          volumeMounts:
+            - name: config
+              mountPath: /etc/sidecar
+      ephemeralContainers:
+        - name: debugger
+          env:
+            - name: LEVEL
+              valueFrom:
+                configMapKeyRef:
+                  name: app-config
+                  key: level
+      volumes:
+        - name: config
+          configMap:
+            name: app-config
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-credentials
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app-config
+`
+	resources, err := parser.Parse(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	relationships := Identify(resources)
+
+	expected := map[string]bool{
+		"USES_SECRET Secret/db-credentials wait-for-db/init":  true,
+		"USES_CONFIG ConfigMap/app-config app/main":           true,
+		"USES_CONFIG ConfigMap/app-config sidecar/main":       true,
+		"USES_CONFIG ConfigMap/app-config debugger/ephemeral": true,
+		"RUNS_AS ServiceAccount/default":                      true,
+	}
+	got := map[string]bool{}
+	for _, rel := range relationships {
+		key := rel.Type + " " + rel.Target.Kind + "/" + rel.Target.Metadata.Name
+		if container, ok := rel.Properties["container"].(string); ok {
+			key += " " + container + "/" + rel.Properties["container_type"].(string)
+		}
+		got[key] = true
+	}
+	for e := range expected {
+		if !got[e] {
+			t.Errorf("missing relationship %s", e)
+		}
+	}
+	if len(got) != len(expected) {
+		t.Errorf("expected %d relationships, but got %v", len(expected), got)
+	}
+}